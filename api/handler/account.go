@@ -2,7 +2,9 @@ package handler
 
 import (
 	"context"
+	"fmt"
 	"net/http"
+	"strconv"
 
 	"github.com/eko/gocache/lib/v4/store"
 	"github.com/labstack/echo/v4"
@@ -42,3 +44,312 @@ func Account(c echo.Context) error {
 
 	return c.JSON(http.StatusOK, accountStats)
 }
+
+func AccountTransactions(c echo.Context) error {
+	cc := c.(*ApiContext)
+
+	address := c.Param("address")
+	addr, err := types.StringToAddress(address)
+	if err != nil {
+		log.Warning("failed to parse account address: %v", err)
+		return c.NoContent(http.StatusBadRequest)
+	}
+
+	limit, _ := GetPagination(c)
+	var cursor *storage.TxCursor
+	if v := c.QueryParam("cursor"); v != "" {
+		cursor, err = storage.ParseTxCursor(v)
+		if err != nil {
+			return c.NoContent(http.StatusBadRequest)
+		}
+	}
+
+	key := fmt.Sprintf("accountTransactions-%s-%d-%s", address, limit, c.QueryParam("cursor"))
+	if cached, err := cc.Cache.Get(context.Background(), key,
+		new(*storage.AccountTransactionList)); err == nil {
+		return c.JSON(http.StatusOK, cached)
+	}
+
+	txs, err := cc.StorageClient.GetAccountTransactions(cc.Storage, addr, uint64(limit), cursor)
+	if err != nil {
+		log.Warning("failed to get account transactions: %v", err)
+		return c.NoContent(http.StatusInternalServerError)
+	}
+
+	if err = cc.Cache.Set(context.Background(), key, txs,
+		store.WithExpiration(cache.ShortExpiration)); err != nil {
+		log.Warning("failed to cache account transactions: %v", err)
+		return c.NoContent(http.StatusInternalServerError)
+	}
+
+	return c.JSON(http.StatusOK, txs)
+}
+
+func AccountBalance(c echo.Context) error {
+	cc := c.(*ApiContext)
+
+	address := c.Param("address")
+	addr, err := types.StringToAddress(address)
+	if err != nil {
+		log.Warning("failed to parse account address: %v", err)
+		return c.NoContent(http.StatusBadRequest)
+	}
+
+	layer := int64(-1)
+	if v := c.QueryParam("layer"); v != "" {
+		layer, err = strconv.ParseInt(v, 10, 32)
+		if err != nil || layer < 0 {
+			return c.NoContent(http.StatusBadRequest)
+		}
+	}
+
+	key := fmt.Sprintf("accountBalance-%s-%d", address, layer)
+	if cached, err := cc.Cache.Get(context.Background(), key, new(*storage.AccountBalance)); err == nil {
+		return c.JSON(http.StatusOK, cached)
+	}
+
+	balance, err := cc.StorageClient.GetAccountBalance(cc.Storage, addr, layer)
+	if err != nil {
+		log.Warning("failed to get account balance: %v", err)
+		return c.NoContent(http.StatusInternalServerError)
+	}
+
+	if err = cc.Cache.Set(context.Background(), key, balance,
+		store.WithExpiration(cache.ShortExpiration)); err != nil {
+		log.Warning("failed to cache account balance: %v", err)
+		return c.NoContent(http.StatusInternalServerError)
+	}
+
+	return c.JSON(http.StatusOK, balance)
+}
+
+func AccountBalanceHistory(c echo.Context) error {
+	cc := c.(*ApiContext)
+
+	address := c.Param("address")
+	addr, err := types.StringToAddress(address)
+	if err != nil {
+		log.Warning("failed to parse account address: %v", err)
+		return c.NoContent(http.StatusBadRequest)
+	}
+
+	bucket := c.QueryParam("bucket")
+	switch bucket {
+	case "":
+		bucket = storage.BucketEpoch
+	case storage.BucketEpoch, storage.BucketLayer:
+	default:
+		return c.NoContent(http.StatusBadRequest)
+	}
+
+	var from, to int64
+	if bucket == storage.BucketEpoch {
+		from, to, err = GetEpochRange(c, "from", "to")
+		if err != nil {
+			return c.NoContent(http.StatusBadRequest)
+		}
+	} else {
+		from, to, err = GetLayerRange(c, "from", "to")
+		if err != nil {
+			return c.NoContent(http.StatusBadRequest)
+		}
+	}
+
+	key := fmt.Sprintf("accountBalanceHistory-%s-%s-%d-%d", address, bucket, from, to)
+	if cached, err := cc.Cache.Get(context.Background(), key, new(*storage.BalanceHistory)); err == nil {
+		return c.JSON(http.StatusOK, cached)
+	}
+
+	history, err := cc.StorageClient.GetAccountBalanceHistory(cc.Storage, addr, from, to, cc.LayersPerEpoch, bucket)
+	if err != nil {
+		log.Warning("failed to get account balance history: %v", err)
+		return c.NoContent(http.StatusInternalServerError)
+	}
+
+	if err = cc.Cache.Set(context.Background(), key, history,
+		store.WithExpiration(cache.ShortExpiration)); err != nil {
+		log.Warning("failed to cache account balance history: %v", err)
+		return c.NoContent(http.StatusInternalServerError)
+	}
+
+	return c.JSON(http.StatusOK, history)
+}
+
+func AccountsTop(c echo.Context) error {
+	cc := c.(*ApiContext)
+	limit, offset := GetPagination(c)
+
+	atEpoch := int64(-1)
+	if v := c.QueryParam("at_epoch"); v != "" {
+		epoch, err := strconv.ParseInt(v, 10, 32)
+		if err != nil || epoch < 0 {
+			return c.NoContent(http.StatusBadRequest)
+		}
+		atEpoch = epoch
+	}
+
+	key := fmt.Sprintf("accountsTop-%d-%d-%d", atEpoch, limit, offset)
+	if cached, err := cc.Cache.Get(context.Background(), key, new(*storage.AccountList)); err == nil {
+		return c.JSON(http.StatusOK, cached)
+	}
+
+	accounts, err := cc.StorageClient.GetTopAccounts(cc.Storage, uint64(limit), uint64(offset),
+		atEpoch, cc.LayersPerEpoch)
+	if err != nil {
+		log.Warning("failed to get top accounts: %v", err)
+		return c.NoContent(http.StatusInternalServerError)
+	}
+
+	// only the latest pages of 20 are re-warmed by the refresh handler
+	var opts []store.Option
+	if atEpoch >= 0 || limit != 20 || offset%20 != 0 || offset >= 1000 {
+		opts = append(opts, store.WithExpiration(cache.ShortExpiration))
+	}
+	if err = cc.Cache.Set(context.Background(), key, accounts, opts...); err != nil {
+		log.Warning("failed to cache top accounts: %v", err)
+		return c.NoContent(http.StatusInternalServerError)
+	}
+
+	cache.LastUpdated.WithLabelValues("/accounts/top").SetToCurrentTime()
+
+	return c.JSON(http.StatusOK, accounts)
+}
+
+func AccountsTopRefresh(c echo.Context) error {
+	cc := c.(*ApiContext)
+
+	go func() {
+		accounts, err := cc.StorageClient.GetTopAccounts(cc.Storage, 1000, 0, -1, cc.LayersPerEpoch)
+		if err != nil {
+			log.Warning("failed to get top accounts: %v", err)
+			return
+		}
+
+		for i := 0; i < len(accounts.Accounts); i += 20 {
+			end := i + 20
+			if end > len(accounts.Accounts) {
+				end = len(accounts.Accounts)
+			}
+			if err = cc.Cache.Set(
+				context.Background(),
+				fmt.Sprintf("accountsTop-%d-%d-%d", -1, 20, i),
+				&storage.AccountList{Accounts: accounts.Accounts[i:end]},
+			); err != nil {
+				log.Warning("failed to cache top accounts: %v", err)
+				return
+			}
+		}
+
+		log.Info("top accounts refreshed")
+		cache.LastUpdated.WithLabelValues("/refresh/accounts/top").SetToCurrentTime()
+	}()
+
+	return c.NoContent(http.StatusOK)
+}
+
+func AccountsDistribution(c echo.Context) error {
+	cc := c.(*ApiContext)
+
+	if cached, err := cc.Cache.Get(context.Background(), "accountsDistribution",
+		new(*storage.AccountsDistribution)); err == nil {
+		return c.JSON(http.StatusOK, cached)
+	}
+
+	distribution, err := cc.StorageClient.GetAccountsDistribution(cc.Storage)
+	if err != nil {
+		log.Warning("failed to get accounts distribution: %v", err)
+		return c.NoContent(http.StatusInternalServerError)
+	}
+
+	if err = cc.Cache.Set(context.Background(), "accountsDistribution", distribution); err != nil {
+		log.Warning("failed to cache accounts distribution: %v", err)
+		return c.NoContent(http.StatusInternalServerError)
+	}
+
+	cache.LastUpdated.WithLabelValues("/accounts/distribution").SetToCurrentTime()
+
+	return c.JSON(http.StatusOK, distribution)
+}
+
+func AccountsDistributionRefresh(c echo.Context) error {
+	cc := c.(*ApiContext)
+
+	go func() {
+		distribution, err := cc.StorageClient.GetAccountsDistribution(cc.Storage)
+		if err != nil {
+			log.Warning("failed to get accounts distribution: %v", err)
+			return
+		}
+
+		if err = cc.Cache.Set(context.Background(), "accountsDistribution", distribution); err != nil {
+			log.Warning("failed to cache accounts distribution: %v", err)
+			return
+		}
+
+		log.Info("accounts distribution refreshed")
+		cache.LastUpdated.WithLabelValues("/refresh/accounts/distribution").SetToCurrentTime()
+	}()
+
+	return c.NoContent(http.StatusOK)
+}
+
+func AccountSmeshers(c echo.Context) error {
+	cc := c.(*ApiContext)
+
+	address := c.Param("address")
+	addr, err := types.StringToAddress(address)
+	if err != nil {
+		log.Warning("failed to parse account address: %v", err)
+		return c.NoContent(http.StatusBadRequest)
+	}
+
+	limit, offset := GetPagination(c)
+
+	key := fmt.Sprintf("accountSmeshers-%s-%d-%d", address, limit, offset)
+	if cached, err := cc.Cache.Get(context.Background(), key, new(*storage.CoinbaseSmeshers)); err == nil {
+		return c.JSON(http.StatusOK, cached)
+	}
+
+	smeshers, err := cc.StorageClient.GetSmeshersByCoinbase(cc.Storage, addr, uint64(limit), uint64(offset),
+		cc.LayersPerEpoch)
+	if err != nil {
+		log.Warning("failed to get account smeshers: %v", err)
+		return c.NoContent(http.StatusInternalServerError)
+	}
+
+	if err = cc.Cache.Set(context.Background(), key, smeshers,
+		store.WithExpiration(cache.ShortExpiration)); err != nil {
+		log.Warning("failed to cache account smeshers: %v", err)
+		return c.NoContent(http.StatusInternalServerError)
+	}
+
+	return c.JSON(http.StatusOK, smeshers)
+}
+
+func AccountsGrowth(c echo.Context) error {
+	cc := c.(*ApiContext)
+
+	from, to, err := GetEpochRange(c, "from_epoch", "to_epoch")
+	if err != nil {
+		return c.NoContent(http.StatusBadRequest)
+	}
+
+	key := fmt.Sprintf("accountsGrowth-%d-%d", from, to)
+	if cached, err := cc.Cache.Get(context.Background(), key, new(*storage.AccountsGrowth)); err == nil {
+		return c.JSON(http.StatusOK, cached)
+	}
+
+	growth, err := cc.StorageClient.GetAccountsGrowth(cc.Storage, from, to, cc.LayersPerEpoch)
+	if err != nil {
+		log.Warning("failed to get accounts growth: %v", err)
+		return c.NoContent(http.StatusInternalServerError)
+	}
+
+	if err = cc.Cache.Set(context.Background(), key, growth,
+		store.WithExpiration(cache.ShortExpiration)); err != nil {
+		log.Warning("failed to cache accounts growth: %v", err)
+		return c.NoContent(http.StatusInternalServerError)
+	}
+
+	return c.JSON(http.StatusOK, growth)
+}
@@ -0,0 +1,53 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/eko/gocache/lib/v4/store"
+	"github.com/labstack/echo/v4"
+	"github.com/spacemeshos/go-spacemesh/log"
+
+	"github.com/spacemeshos/explorer-backend/api/cache"
+	"github.com/spacemeshos/explorer-backend/api/storage"
+)
+
+func IssuanceAudit(c echo.Context) error {
+	cc := c.(*ApiContext)
+
+	epoch, err := strconv.ParseInt(c.QueryParam("epoch"), 10, 32)
+	currentEpoch := cc.StorageClient.CurrentEpoch(cc.LayersPerEpoch)
+	if err != nil || epoch < 0 || epoch > currentEpoch {
+		return c.NoContent(http.StatusBadRequest)
+	}
+
+	key := fmt.Sprintf("issuanceAudit-%d", epoch)
+	if cached, err := cc.Cache.Get(context.Background(), key, new(*storage.IssuanceAudit)); err == nil {
+		return c.JSON(http.StatusOK, cached)
+	}
+
+	audit, err := cc.StorageClient.GetIssuanceAudit(cc.Storage, epoch, cc.LayersPerEpoch)
+	if err != nil {
+		log.Warning("failed to get issuance audit: %v", err)
+		return c.NoContent(http.StatusInternalServerError)
+	}
+
+	// epochs up to the last applied layer never change, later layers are not audited yet
+	lastApplied, err := cc.StorageClient.GetLastAppliedLayer(cc.Storage)
+	if err != nil {
+		log.Warning("failed to get last applied layer: %v", err)
+		return c.NoContent(http.StatusInternalServerError)
+	}
+	var opts []store.Option
+	if (epoch+1)*cc.LayersPerEpoch-1 > lastApplied {
+		opts = append(opts, store.WithExpiration(cache.ShortExpiration))
+	}
+	if err = cc.Cache.Set(context.Background(), key, audit, opts...); err != nil {
+		log.Warning("failed to cache issuance audit: %v", err)
+		return c.NoContent(http.StatusInternalServerError)
+	}
+
+	return c.JSON(http.StatusOK, audit)
+}
@@ -2,8 +2,11 @@ package handler
 
 import (
 	"context"
+	"fmt"
 	"net/http"
+	"strconv"
 
+	"github.com/eko/gocache/lib/v4/store"
 	"github.com/labstack/echo/v4"
 	"github.com/spacemeshos/go-spacemesh/log"
 
@@ -14,6 +17,10 @@ import (
 func Circulation(c echo.Context) error {
 	cc := c.(*ApiContext)
 
+	if c.QueryParam("layer") != "" || c.QueryParam("epoch") != "" {
+		return circulationAt(c)
+	}
+
 	if cached, err := cc.Cache.Get(context.Background(), "circulation", new(*storage.Circulation)); err == nil {
 		return c.JSON(http.StatusOK, cached)
 	}
@@ -33,6 +40,93 @@ func Circulation(c echo.Context) error {
 	return c.JSON(http.StatusOK, circulation)
 }
 
+// circulationAt serves circulation at a past layer, or at the end of a past epoch.
+func circulationAt(c echo.Context) error {
+	cc := c.(*ApiContext)
+	current := cc.StorageClient.CurrentLayer()
+
+	var layer int64
+	var err error
+	switch {
+	case c.QueryParam("layer") != "" && c.QueryParam("epoch") != "":
+		return c.NoContent(http.StatusBadRequest)
+	case c.QueryParam("layer") != "":
+		layer, err = strconv.ParseInt(c.QueryParam("layer"), 10, 32)
+	default:
+		var epoch int64
+		epoch, err = strconv.ParseInt(c.QueryParam("epoch"), 10, 32)
+		layer = min((epoch+1)*cc.LayersPerEpoch-1, current)
+	}
+	if err != nil || layer < 0 || layer > current {
+		return c.NoContent(http.StatusBadRequest)
+	}
+
+	key := fmt.Sprintf("circulation-%d", layer)
+	if cached, err := cc.Cache.Get(context.Background(), key, new(*storage.Circulation)); err == nil {
+		return c.JSON(http.StatusOK, cached)
+	}
+
+	circulation, err := cc.StorageClient.GetCirculationAtLayer(cc.Storage, layer)
+	if err != nil {
+		log.Warning("failed to get circulation: %v", err)
+		return c.NoContent(http.StatusInternalServerError)
+	}
+
+	// applied layers never change, rewards of later layers may still arrive
+	lastApplied, err := cc.StorageClient.GetLastAppliedLayer(cc.Storage)
+	if err != nil {
+		log.Warning("failed to get last applied layer: %v", err)
+		return c.NoContent(http.StatusInternalServerError)
+	}
+	var opts []store.Option
+	if layer > lastApplied {
+		opts = append(opts, store.WithExpiration(cache.ShortExpiration))
+	}
+	if err = cc.Cache.Set(context.Background(), key, circulation, opts...); err != nil {
+		log.Warning("failed to cache circulation: %v", err)
+		return c.NoContent(http.StatusInternalServerError)
+	}
+
+	return c.JSON(http.StatusOK, circulation)
+}
+
+func CirculationHistory(c echo.Context) error {
+	cc := c.(*ApiContext)
+
+	from, to, err := GetEpochRange(c, "from_epoch", "to_epoch")
+	if err != nil {
+		return c.NoContent(http.StatusBadRequest)
+	}
+
+	key := fmt.Sprintf("circulationHistory-%d-%d", from, to)
+	if cached, err := cc.Cache.Get(context.Background(), key, new(*storage.CirculationHistory)); err == nil {
+		return c.JSON(http.StatusOK, cached)
+	}
+
+	history, err := cc.StorageClient.GetCirculationHistory(cc.Storage, from, to, cc.LayersPerEpoch)
+	if err != nil {
+		log.Warning("failed to get circulation history: %v", err)
+		return c.NoContent(http.StatusInternalServerError)
+	}
+
+	// epochs up to the last applied layer never change, rewards of later layers may still arrive
+	lastApplied, err := cc.StorageClient.GetLastAppliedLayer(cc.Storage)
+	if err != nil {
+		log.Warning("failed to get last applied layer: %v", err)
+		return c.NoContent(http.StatusInternalServerError)
+	}
+	var opts []store.Option
+	if (to+1)*cc.LayersPerEpoch-1 > lastApplied {
+		opts = append(opts, store.WithExpiration(cache.ShortExpiration))
+	}
+	if err = cc.Cache.Set(context.Background(), key, history, opts...); err != nil {
+		log.Warning("failed to cache circulation history: %v", err)
+		return c.NoContent(http.StatusInternalServerError)
+	}
+
+	return c.JSON(http.StatusOK, history)
+}
+
 func CirculationRefresh(c echo.Context) error {
 	cc := c.(*ApiContext)
 
@@ -54,3 +148,25 @@ func CirculationRefresh(c echo.Context) error {
 
 	return c.NoContent(http.StatusOK)
 }
+
+func Supply(c echo.Context) error {
+	cc := c.(*ApiContext)
+
+	if cached, err := cc.Cache.Get(context.Background(), "supply", new(*storage.Supply)); err == nil {
+		return c.JSON(http.StatusOK, cached)
+	}
+
+	supply, err := cc.StorageClient.GetSupply(cc.Storage)
+	if err != nil {
+		log.Warning("failed to get supply: %v", err)
+		return c.NoContent(http.StatusInternalServerError)
+	}
+
+	if err = cc.Cache.Set(context.Background(), "supply", supply,
+		store.WithExpiration(cache.ShortExpiration)); err != nil {
+		log.Warning("failed to cache supply: %v", err)
+		return c.NoContent(http.StatusInternalServerError)
+	}
+
+	return c.JSON(http.StatusOK, supply)
+}
@@ -0,0 +1,43 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/eko/gocache/lib/v4/store"
+	"github.com/labstack/echo/v4"
+	"github.com/spacemeshos/go-spacemesh/log"
+
+	"github.com/spacemeshos/explorer-backend/api/cache"
+	"github.com/spacemeshos/explorer-backend/api/storage"
+)
+
+func EmissionProjection(c echo.Context) error {
+	cc := c.(*ApiContext)
+
+	to, err := strconv.ParseInt(c.QueryParam("to_epoch"), 10, 32)
+	if err != nil || to < 0 || to >= epochRangeLimit {
+		return c.NoContent(http.StatusBadRequest)
+	}
+
+	key := fmt.Sprintf("emissionProjection-%d", to)
+	if cached, err := cc.Cache.Get(context.Background(), key, new(*storage.EmissionProjection)); err == nil {
+		return c.JSON(http.StatusOK, cached)
+	}
+
+	projection, err := cc.StorageClient.GetEmissionProjection(cc.Storage, to, cc.LayersPerEpoch)
+	if err != nil {
+		log.Warning("failed to get emission projection: %v", err)
+		return c.NoContent(http.StatusInternalServerError)
+	}
+
+	if err = cc.Cache.Set(context.Background(), key, projection,
+		store.WithExpiration(cache.ShortExpiration)); err != nil {
+		log.Warning("failed to cache emission projection: %v", err)
+		return c.NoContent(http.StatusInternalServerError)
+	}
+
+	return c.JSON(http.StatusOK, projection)
+}
@@ -5,7 +5,9 @@ import (
 	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
 
+	"github.com/eko/gocache/lib/v4/store"
 	"github.com/labstack/echo/v4"
 	"github.com/spacemeshos/go-spacemesh/log"
 
@@ -20,18 +22,23 @@ func Epoch(c echo.Context) error {
 		return c.NoContent(http.StatusBadRequest)
 	}
 
-	if cached, err := cc.Cache.Get(context.Background(), "epochStats"+c.Param("id"),
-		new(*storage.EpochStats)); err == nil {
+	excludeMalicious, err := getExcludeMalicious(c)
+	if err != nil {
+		return c.NoContent(http.StatusBadRequest)
+	}
+
+	key := epochCacheKey(c.Param("id"), excludeMalicious)
+	if cached, err := cc.Cache.Get(context.Background(), key, new(*storage.EpochStats)); err == nil {
 		return c.JSON(http.StatusOK, cached)
 	}
 
-	epochStats, err := cc.StorageClient.GetEpochStats(cc.Storage, int64(id), cc.LayersPerEpoch)
+	epochStats, err := cc.StorageClient.GetEpochStats(cc.Storage, int64(id), cc.LayersPerEpoch, excludeMalicious)
 	if err != nil {
 		log.Warning("failed to get epoch stats: %v", err)
 		return c.NoContent(http.StatusInternalServerError)
 	}
 
-	if err = cc.Cache.Set(context.Background(), "epochStats"+c.Param("id"), epochStats); err != nil {
+	if err = cc.Cache.Set(context.Background(), key, epochStats); err != nil {
 		log.Warning("failed to cache epoch stats: %v", err)
 		return c.NoContent(http.StatusInternalServerError)
 	}
@@ -49,15 +56,19 @@ func EpochRefresh(c echo.Context) error {
 	}
 
 	go func() {
-		epochStats, err := cc.StorageClient.GetEpochStats(cc.Storage, int64(id), cc.LayersPerEpoch)
-		if err != nil {
-			log.Warning("failed to get epoch stats: %v", err)
-			return
-		}
+		for _, excludeMalicious := range []bool{false, true} {
+			epochStats, err := cc.StorageClient.GetEpochStats(cc.Storage, int64(id), cc.LayersPerEpoch,
+				excludeMalicious)
+			if err != nil {
+				log.Warning("failed to get epoch stats: %v", err)
+				return
+			}
 
-		if err = cc.Cache.Set(context.Background(), "epochStats"+c.Param("id"), epochStats); err != nil {
-			log.Warning("failed to cache epoch stats: %v", err)
-			return
+			if err = cc.Cache.Set(context.Background(), epochCacheKey(c.Param("id"), excludeMalicious),
+				epochStats); err != nil {
+				log.Warning("failed to cache epoch stats: %v", err)
+				return
+			}
 		}
 
 		log.Info("epoch %d refreshed", id)
@@ -67,6 +78,32 @@ func EpochRefresh(c echo.Context) error {
 	return c.NoContent(http.StatusOK)
 }
 
+// getExcludeMalicious parses the optional exclude_malicious query parameter.
+func getExcludeMalicious(c echo.Context) (bool, error) {
+	if v := c.QueryParam("exclude_malicious"); v != "" {
+		return strconv.ParseBool(v)
+	}
+	return false, nil
+}
+
+func epochCacheKey(id string, excludeMalicious bool) string {
+	if excludeMalicious {
+		return "epochStats" + id + "-excludeMalicious"
+	}
+	return "epochStats" + id
+}
+
+func decentralCacheKey(id, groupBy string, excludeMalicious bool) string {
+	key := "epochStatsDecentral" + id
+	if groupBy != "" {
+		key += "-" + groupBy
+	}
+	if excludeMalicious {
+		key += "-excludeMalicious"
+	}
+	return key
+}
+
 func EpochDecentral(c echo.Context) error {
 	cc := c.(*ApiContext)
 	id, err := strconv.Atoi(c.Param("id"))
@@ -74,18 +111,30 @@ func EpochDecentral(c echo.Context) error {
 		return c.NoContent(http.StatusBadRequest)
 	}
 
-	if cached, err := cc.Cache.Get(context.Background(), "epochStatsDecentral"+c.Param("id"),
-		new(*storage.EpochStats)); err == nil {
+	groupBy := c.QueryParam("group_by")
+	switch groupBy {
+	case "", storage.DecentralGroupByCoinbase, storage.DecentralGroupByMarriage:
+	default:
+		return c.NoContent(http.StatusBadRequest)
+	}
+
+	excludeMalicious, err := getExcludeMalicious(c)
+	if err != nil {
+		return c.NoContent(http.StatusBadRequest)
+	}
+
+	key := decentralCacheKey(c.Param("id"), groupBy, excludeMalicious)
+	if cached, err := cc.Cache.Get(context.Background(), key, new(*storage.EpochStats)); err == nil {
 		return c.JSON(http.StatusOK, cached)
 	}
 
-	epochStats, err := cc.StorageClient.GetEpochDecentralRatio(cc.Storage, int64(id))
+	epochStats, err := cc.StorageClient.GetEpochDecentralRatio(cc.Storage, int64(id), groupBy, excludeMalicious)
 	if err != nil {
 		log.Warning("failed to get epoch stats: %v", err)
 		return c.NoContent(http.StatusInternalServerError)
 	}
 
-	if err = cc.Cache.Set(context.Background(), "epochStatsDecentral"+c.Param("id"), epochStats); err != nil {
+	if err = cc.Cache.Set(context.Background(), key, epochStats); err != nil {
 		log.Warning("failed to cache epoch stats: %v", err)
 		return c.NoContent(http.StatusInternalServerError)
 	}
@@ -103,15 +152,21 @@ func EpochDecentralRefresh(c echo.Context) error {
 	}
 
 	go func() {
-		epochStats, err := cc.StorageClient.GetEpochDecentralRatio(cc.Storage, int64(id))
-		if err != nil {
-			log.Warning("failed to get epoch stats: %v", err)
-			return
-		}
+		for _, groupBy := range []string{"", storage.DecentralGroupByCoinbase, storage.DecentralGroupByMarriage} {
+			for _, excludeMalicious := range []bool{false, true} {
+				epochStats, err := cc.StorageClient.GetEpochDecentralRatio(cc.Storage, int64(id), groupBy,
+					excludeMalicious)
+				if err != nil {
+					log.Warning("failed to get epoch stats: %v", err)
+					return
+				}
 
-		if err = cc.Cache.Set(context.Background(), "epochStatsDecentral"+c.Param("id"), epochStats); err != nil {
-			log.Warning("failed to cache epoch stats: %v", err)
-			return
+				if err = cc.Cache.Set(context.Background(),
+					decentralCacheKey(c.Param("id"), groupBy, excludeMalicious), epochStats); err != nil {
+					log.Warning("failed to cache epoch stats: %v", err)
+					return
+				}
+			}
 		}
 
 		log.Info("epoch %d decentral refreshed", id)
@@ -120,3 +175,165 @@ func EpochDecentralRefresh(c echo.Context) error {
 
 	return c.NoContent(http.StatusOK)
 }
+
+func EpochTransactions(c echo.Context) error {
+	cc := c.(*ApiContext)
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.NoContent(http.StatusBadRequest)
+	}
+
+	limit, offset := GetPagination(c)
+
+	if cached, err := cc.Cache.Get(context.Background(),
+		fmt.Sprintf("transactions-epoch-%d-%d-%d", id, limit, offset), new(*storage.TransactionList)); err == nil {
+		return c.JSON(http.StatusOK, cached)
+	}
+
+	txs, err := cc.StorageClient.GetTransactionsByEpoch(cc.Storage, int64(id), cc.LayersPerEpoch,
+		uint64(limit), uint64(offset))
+	if err != nil {
+		log.Warning("failed to get epoch transactions: %v", err)
+		return c.NoContent(http.StatusInternalServerError)
+	}
+
+	if err = cc.Cache.Set(context.Background(), fmt.Sprintf("transactions-epoch-%d-%d-%d", id, limit, offset),
+		txs, store.WithExpiration(cache.ShortExpiration)); err != nil {
+		log.Warning("failed to cache epoch transactions: %v", err)
+		return c.NoContent(http.StatusInternalServerError)
+	}
+
+	return c.JSON(http.StatusOK, txs)
+}
+
+func Epochs(c echo.Context) error {
+	cc := c.(*ApiContext)
+	currentEpoch := cc.StorageClient.CurrentEpoch(cc.LayersPerEpoch)
+
+	from, to, err := GetEpochRange(c, "from", "to")
+	if err != nil {
+		return c.NoContent(http.StatusBadRequest)
+	}
+
+	// finished epochs are cached one by one, so only the missing span is computed
+	epochs := make([]*storage.EpochStats, to-from+1)
+	missingFrom, missingTo := int64(-1), int64(-1)
+	for epoch := from; epoch <= to; epoch++ {
+		var stats *storage.EpochStats
+		if _, err := cc.Cache.Get(context.Background(), fmt.Sprintf("epochStats%d", epoch), &stats); err == nil &&
+			stats != nil {
+			epochs[epoch-from] = stats
+			continue
+		}
+		if missingFrom < 0 {
+			missingFrom = epoch
+		}
+		missingTo = epoch
+	}
+
+	if missingFrom >= 0 {
+		computed, err := cc.StorageClient.GetEpochsStats(cc.Storage, missingFrom, missingTo, cc.LayersPerEpoch, false)
+		if err != nil {
+			log.Warning("failed to get epochs stats: %v", err)
+			return c.NoContent(http.StatusInternalServerError)
+		}
+		for _, stats := range computed {
+			epoch := int64(stats.Epoch)
+			if epochs[epoch-from] != nil {
+				continue
+			}
+			epochs[epoch-from] = stats
+
+			var opts []store.Option
+			if epoch >= currentEpoch {
+				opts = append(opts, store.WithExpiration(cache.ShortExpiration))
+			}
+			if err = cc.Cache.Set(context.Background(), fmt.Sprintf("epochStats%d", epoch), stats,
+				opts...); err != nil {
+				log.Warning("failed to cache epoch stats: %v", err)
+				return c.NoContent(http.StatusInternalServerError)
+			}
+		}
+	}
+
+	fields := c.QueryParam("fields")
+	if fields == "" {
+		return c.JSON(http.StatusOK, epochs)
+	}
+
+	result, err := selectFields(epochs, append(strings.Split(fields, ","), "epoch"))
+	if err != nil {
+		log.Warning("failed to select epochs fields: %v", err)
+		return c.NoContent(http.StatusInternalServerError)
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
+
+func EpochYield(c echo.Context) error {
+	cc := c.(*ApiContext)
+	// yield is annualised from a whole epoch of rewards, so only completed epochs are accepted
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id < 0 || int64(id) >= cc.StorageClient.CurrentEpoch(cc.LayersPerEpoch) {
+		return c.NoContent(http.StatusBadRequest)
+	}
+
+	if cached, err := cc.Cache.Get(context.Background(), "epochYield"+c.Param("id"),
+		new(*storage.Yield)); err == nil {
+		return c.JSON(http.StatusOK, cached)
+	}
+
+	yield, err := cc.StorageClient.GetEpochYield(cc.Storage, int64(id), cc.LayersPerEpoch)
+	if err != nil {
+		log.Warning("failed to get epoch yield: %v", err)
+		return c.NoContent(http.StatusInternalServerError)
+	}
+
+	// rewards of the epoch's last layers may not be applied yet right after it ends
+	lastApplied, err := cc.StorageClient.GetLastAppliedLayer(cc.Storage)
+	if err != nil {
+		log.Warning("failed to get last applied layer: %v", err)
+		return c.NoContent(http.StatusInternalServerError)
+	}
+	var opts []store.Option
+	if int64(id+1)*cc.LayersPerEpoch-1 > lastApplied {
+		opts = append(opts, store.WithExpiration(cache.ShortExpiration))
+	}
+	if err = cc.Cache.Set(context.Background(), "epochYield"+c.Param("id"), yield, opts...); err != nil {
+		log.Warning("failed to cache epoch yield: %v", err)
+		return c.NoContent(http.StatusInternalServerError)
+	}
+
+	return c.JSON(http.StatusOK, yield)
+}
+
+func EpochAtxTiming(c echo.Context) error {
+	cc := c.(*ApiContext)
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id < 1 {
+		return c.NoContent(http.StatusBadRequest)
+	}
+
+	key := fmt.Sprintf("epochAtxTiming%d", id)
+	if cached, err := cc.Cache.Get(context.Background(), key, new(*storage.AtxTiming)); err == nil {
+		return c.JSON(http.StatusOK, cached)
+	}
+
+	timing, err := cc.StorageClient.GetAtxTiming(cc.Storage, int64(id), cc.LayersPerEpoch)
+	if err != nil {
+		log.Warning("failed to get epoch atx timing: %v", err)
+		return c.NoContent(http.StatusInternalServerError)
+	}
+
+	// late atxs may still arrive until the epoch is over
+	var opts []store.Option
+	if int64(id) >= cc.StorageClient.CurrentEpoch(cc.LayersPerEpoch) {
+		opts = append(opts, store.WithExpiration(cache.ShortExpiration))
+	}
+	if err = cc.Cache.Set(context.Background(), key, timing, opts...); err != nil {
+		log.Warning("failed to cache epoch atx timing: %v", err)
+		return c.NoContent(http.StatusInternalServerError)
+	}
+
+	return c.JSON(http.StatusOK, timing)
+}
@@ -1,7 +1,10 @@
 package handler
 
 import (
+	"encoding/json"
+	"fmt"
 	"strconv"
+	"strings"
 
 	"github.com/eko/gocache/lib/v4/marshaler"
 	"github.com/labstack/echo/v4"
@@ -21,6 +24,12 @@ type ApiContext struct {
 func GetPagination(c echo.Context) (limit, offset int64) {
 	limit = 20
 	offset = 0
+	if size := c.QueryParam("limit"); size != "" {
+		limit, _ = strconv.ParseInt(size, 10, 32)
+		if limit <= 0 || limit > 100 {
+			limit = 20
+		}
+	}
 	if size := c.QueryParam("offset"); size != "" {
 		offset, _ = strconv.ParseInt(size, 10, 32)
 		if offset <= 0 {
@@ -29,3 +38,83 @@ func GetPagination(c echo.Context) (limit, offset int64) {
 	}
 	return limit, offset
 }
+
+// epochRangeLimit caps the number of epochs in a single range request.
+const epochRangeLimit = 1000
+
+// GetEpochRange parses an inclusive epoch range capped at the current epoch,
+// defaulting to genesis..current epoch.
+func GetEpochRange(c echo.Context, fromParam, toParam string) (from, to int64, err error) {
+	cc := c.(*ApiContext)
+	current := cc.StorageClient.CurrentEpoch(cc.LayersPerEpoch)
+	from, to = 0, current
+	if v := c.QueryParam(fromParam); v != "" {
+		from, err = strconv.ParseInt(v, 10, 32)
+		if err != nil {
+			return 0, 0, err
+		}
+	}
+	if v := c.QueryParam(toParam); v != "" {
+		to, err = strconv.ParseInt(v, 10, 32)
+		if err != nil {
+			return 0, 0, err
+		}
+		to = min(to, current)
+	}
+	if from < 0 || from > current || to < from || to-from >= epochRangeLimit {
+		return 0, 0, fmt.Errorf("invalid epoch range %d..%d", from, to)
+	}
+	return from, to, nil
+}
+
+// layerRangeLimit caps the number of layers in a single range request.
+const layerRangeLimit = 10000
+
+// GetLayerRange parses an inclusive layer range capped at the current layer,
+// defaulting to the last layerRangeLimit layers.
+func GetLayerRange(c echo.Context, fromParam, toParam string) (from, to int64, err error) {
+	cc := c.(*ApiContext)
+	current := cc.StorageClient.CurrentLayer()
+	to = current
+	if v := c.QueryParam(toParam); v != "" {
+		to, err = strconv.ParseInt(v, 10, 32)
+		if err != nil {
+			return 0, 0, err
+		}
+		to = min(to, current)
+	}
+	from = max(to-layerRangeLimit+1, 0)
+	if v := c.QueryParam(fromParam); v != "" {
+		from, err = strconv.ParseInt(v, 10, 32)
+		if err != nil {
+			return 0, 0, err
+		}
+	}
+	if from < 0 || to < from || to-from >= layerRangeLimit {
+		return 0, 0, fmt.Errorf("invalid layer range %d..%d", from, to)
+	}
+	return from, to, nil
+}
+
+// selectFields keeps only the given json fields of every item.
+func selectFields[T any](items []T, fields []string) ([]map[string]any, error) {
+	result := make([]map[string]any, 0, len(items))
+	for _, item := range items {
+		data, err := json.Marshal(item)
+		if err != nil {
+			return nil, err
+		}
+		var all map[string]any
+		if err = json.Unmarshal(data, &all); err != nil {
+			return nil, err
+		}
+		selected := make(map[string]any, len(fields))
+		for _, field := range fields {
+			if v, ok := all[strings.TrimSpace(field)]; ok {
+				selected[strings.TrimSpace(field)] = v
+			}
+		}
+		result = append(result, selected)
+	}
+	return result, nil
+}
@@ -2,6 +2,7 @@ package handler
 
 import (
 	"context"
+	"fmt"
 	"net/http"
 	"strconv"
 
@@ -16,28 +17,93 @@ import (
 func Layer(c echo.Context) error {
 	cc := c.(*ApiContext)
 	lid, err := strconv.Atoi(c.Param("id"))
-	if err != nil {
+	if err != nil || lid < 0 || int64(lid) > cc.StorageClient.CurrentLayer() {
 		return c.NoContent(http.StatusBadRequest)
 	}
 
-	if cached, err := cc.Cache.Get(context.Background(), "layerStats"+c.Param("id"),
-		new(*storage.LayerStats)); err == nil {
+	if cached, err := cc.Cache.Get(context.Background(), "layerDetail"+c.Param("id"),
+		new(*storage.LayerDetail)); err == nil {
 		return c.JSON(http.StatusOK, cached)
 	}
 
-	layerStats, err := cc.StorageClient.GetLayerStats(cc.Storage, int64(lid))
+	layerDetail, err := cc.StorageClient.GetLayerDetail(cc.Storage, int64(lid))
 	if err != nil {
 		log.Warning("failed to get layer stats: %v", err)
 		return c.NoContent(http.StatusInternalServerError)
 	}
 
-	if err = cc.Cache.Set(context.Background(), "layerStats"+c.Param("id"),
-		layerStats, store.WithExpiration(cache.ShortExpiration)); err != nil {
+	if err = cc.Cache.Set(context.Background(), "layerDetail"+c.Param("id"),
+		layerDetail, store.WithExpiration(cache.ShortExpiration)); err != nil {
 		log.Warning("failed to cache layer stats: %v", err)
 		return c.NoContent(http.StatusInternalServerError)
 	}
 
 	cache.LastUpdated.WithLabelValues("/layer/" + c.Param("id")).SetToCurrentTime()
 
-	return c.JSON(http.StatusOK, layerStats)
+	return c.JSON(http.StatusOK, layerDetail)
+}
+
+func LayerTransactions(c echo.Context) error {
+	cc := c.(*ApiContext)
+	lid, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.NoContent(http.StatusBadRequest)
+	}
+
+	limit, offset := GetPagination(c)
+
+	if cached, err := cc.Cache.Get(context.Background(),
+		fmt.Sprintf("transactions-layer-%d-%d-%d", lid, limit, offset), new(*storage.TransactionList)); err == nil {
+		return c.JSON(http.StatusOK, cached)
+	}
+
+	txs, err := cc.StorageClient.GetTransactionsByLayer(cc.Storage, int64(lid), uint64(limit), uint64(offset))
+	if err != nil {
+		log.Warning("failed to get layer transactions: %v", err)
+		return c.NoContent(http.StatusInternalServerError)
+	}
+
+	if err = cc.Cache.Set(context.Background(), fmt.Sprintf("transactions-layer-%d-%d-%d", lid, limit, offset),
+		txs, store.WithExpiration(cache.ShortExpiration)); err != nil {
+		log.Warning("failed to cache layer transactions: %v", err)
+		return c.NoContent(http.StatusInternalServerError)
+	}
+
+	return c.JSON(http.StatusOK, txs)
+}
+
+func Layers(c echo.Context) error {
+	cc := c.(*ApiContext)
+
+	from, to, err := GetLayerRange(c, "from", "to")
+	if err != nil {
+		return c.NoContent(http.StatusBadRequest)
+	}
+	limit := int64(100)
+	if v := c.QueryParam("limit"); v != "" {
+		limit, err = strconv.ParseInt(v, 10, 32)
+		if err != nil || limit <= 0 || limit > 1000 {
+			return c.NoContent(http.StatusBadRequest)
+		}
+	}
+	from = max(from, to-limit+1)
+
+	key := fmt.Sprintf("layers-%d-%d", from, to)
+	if cached, err := cc.Cache.Get(context.Background(), key, new(*storage.LayerList)); err == nil {
+		return c.JSON(http.StatusOK, cached)
+	}
+
+	layers, err := cc.StorageClient.GetLayers(cc.Storage, from, to)
+	if err != nil {
+		log.Warning("failed to get layers: %v", err)
+		return c.NoContent(http.StatusInternalServerError)
+	}
+
+	if err = cc.Cache.Set(context.Background(), key, layers,
+		store.WithExpiration(cache.ShortExpiration)); err != nil {
+		log.Warning("failed to cache layers: %v", err)
+		return c.NoContent(http.StatusInternalServerError)
+	}
+
+	return c.JSON(http.StatusOK, layers)
 }
@@ -0,0 +1,38 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/eko/gocache/lib/v4/store"
+	"github.com/labstack/echo/v4"
+	"github.com/spacemeshos/go-spacemesh/log"
+
+	"github.com/spacemeshos/explorer-backend/api/cache"
+	"github.com/spacemeshos/explorer-backend/api/storage"
+)
+
+func Malfeasance(c echo.Context) error {
+	cc := c.(*ApiContext)
+	limit, offset := GetPagination(c)
+
+	key := fmt.Sprintf("malfeasance-%d-%d", limit, offset)
+	if cached, err := cc.Cache.Get(context.Background(), key, new(*storage.MalfeasanceList)); err == nil {
+		return c.JSON(http.StatusOK, cached)
+	}
+
+	identities, err := cc.StorageClient.GetMalfeasance(cc.Storage, uint64(limit), uint64(offset))
+	if err != nil {
+		log.Warning("failed to get malicious identities: %v", err)
+		return c.NoContent(http.StatusInternalServerError)
+	}
+
+	if err = cc.Cache.Set(context.Background(), key, identities,
+		store.WithExpiration(cache.ShortExpiration)); err != nil {
+		log.Warning("failed to cache malicious identities: %v", err)
+		return c.NoContent(http.StatusInternalServerError)
+	}
+
+	return c.JSON(http.StatusOK, identities)
+}
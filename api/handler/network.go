@@ -0,0 +1,42 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/eko/gocache/lib/v4/store"
+	"github.com/labstack/echo/v4"
+	"github.com/spacemeshos/go-spacemesh/log"
+
+	"github.com/spacemeshos/explorer-backend/api/cache"
+	"github.com/spacemeshos/explorer-backend/api/storage"
+)
+
+func NetworkCapacity(c echo.Context) error {
+	cc := c.(*ApiContext)
+
+	from, to, err := GetEpochRange(c, "from_epoch", "to_epoch")
+	if err != nil {
+		return c.NoContent(http.StatusBadRequest)
+	}
+
+	key := fmt.Sprintf("networkCapacity-%d-%d", from, to)
+	if cached, err := cc.Cache.Get(context.Background(), key, new(*storage.NetworkCapacity)); err == nil {
+		return c.JSON(http.StatusOK, cached)
+	}
+
+	capacity, err := cc.StorageClient.GetNetworkCapacity(cc.Storage, from, to)
+	if err != nil {
+		log.Warning("failed to get network capacity: %v", err)
+		return c.NoContent(http.StatusInternalServerError)
+	}
+
+	if err = cc.Cache.Set(context.Background(), key, capacity,
+		store.WithExpiration(cache.ShortExpiration)); err != nil {
+		log.Warning("failed to cache network capacity: %v", err)
+		return c.NoContent(http.StatusInternalServerError)
+	}
+
+	return c.JSON(http.StatusOK, capacity)
+}
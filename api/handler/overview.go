@@ -18,7 +18,7 @@ func Overview(c echo.Context) error {
 		return c.JSON(http.StatusOK, cached)
 	}
 
-	overview, err := cc.StorageClient.Overview(cc.Storage)
+	overview, err := cc.StorageClient.Overview(cc.Storage, cc.LayersPerEpoch)
 	if err != nil {
 		log.Warning("failed to get overview: %v", err)
 		return c.NoContent(http.StatusInternalServerError)
@@ -38,7 +38,7 @@ func OverviewRefresh(c echo.Context) error {
 	cc := c.(*ApiContext)
 
 	go func() {
-		overview, err := cc.StorageClient.Overview(cc.Storage)
+		overview, err := cc.StorageClient.Overview(cc.Storage, cc.LayersPerEpoch)
 		if err != nil {
 			log.Warning("failed to get overview: %v", err)
 			return
@@ -0,0 +1,42 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/eko/gocache/lib/v4/store"
+	"github.com/labstack/echo/v4"
+	"github.com/spacemeshos/go-spacemesh/log"
+
+	"github.com/spacemeshos/explorer-backend/api/cache"
+	"github.com/spacemeshos/explorer-backend/api/storage"
+)
+
+func RewardsComposition(c echo.Context) error {
+	cc := c.(*ApiContext)
+
+	from, to, err := GetEpochRange(c, "from_epoch", "to_epoch")
+	if err != nil {
+		return c.NoContent(http.StatusBadRequest)
+	}
+
+	key := fmt.Sprintf("rewardsComposition-%d-%d", from, to)
+	if cached, err := cc.Cache.Get(context.Background(), key, new(*storage.RewardsComposition)); err == nil {
+		return c.JSON(http.StatusOK, cached)
+	}
+
+	composition, err := cc.StorageClient.GetRewardsComposition(cc.Storage, from, to, cc.LayersPerEpoch)
+	if err != nil {
+		log.Warning("failed to get rewards composition: %v", err)
+		return c.NoContent(http.StatusInternalServerError)
+	}
+
+	if err = cc.Cache.Set(context.Background(), key, composition,
+		store.WithExpiration(cache.ShortExpiration)); err != nil {
+		log.Warning("failed to cache rewards composition: %v", err)
+		return c.NoContent(http.StatusInternalServerError)
+	}
+
+	return c.JSON(http.StatusOK, composition)
+}
@@ -2,6 +2,7 @@ package handler
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
@@ -15,22 +16,76 @@ import (
 	"github.com/spacemeshos/explorer-backend/api/storage"
 )
 
+// smesherRefreshQueries are the sort orders pre-warmed by the refresh handlers.
+var smesherRefreshQueries = []storage.SmesherQuery{
+	{},
+	{Sort: storage.SmesherSortNumUnits, Order: storage.OrderDesc},
+	{Sort: storage.SmesherSortRewardsSum, Order: storage.OrderDesc},
+	{Sort: storage.SmesherSortAtxs, Order: storage.OrderDesc},
+}
+
+// smesherCacheOptions expires pages the refresh handlers do not re-warm.
+func smesherCacheOptions(query storage.SmesherQuery, limit, offset int64) []store.Option {
+	if limit == 20 && offset%20 == 0 && offset < 1000 {
+		for _, q := range smesherRefreshQueries {
+			if q.String() == query.String() {
+				return nil
+			}
+		}
+	}
+	return []store.Option{store.WithExpiration(cache.ShortExpiration)}
+}
+
+func getSmesherQuery(c echo.Context) (storage.SmesherQuery, error) {
+	query := storage.SmesherQuery{
+		Sort:  c.QueryParam("sort"),
+		Order: c.QueryParam("order"),
+	}
+	if v := c.QueryParam("coinbase"); v != "" {
+		addr, err := types.StringToAddress(v)
+		if err != nil {
+			return query, err
+		}
+		query.Coinbase = &addr
+	}
+	if v := c.QueryParam("min_units"); v != "" {
+		units, err := strconv.ParseUint(v, 10, 32)
+		if err != nil {
+			return query, err
+		}
+		query.MinUnits = units
+	}
+	if v := c.QueryParam("max_units"); v != "" {
+		units, err := strconv.ParseUint(v, 10, 32)
+		if err != nil {
+			return query, err
+		}
+		query.MaxUnits = units
+	}
+	return query, query.Validate()
+}
+
 func Smeshers(c echo.Context) error {
 	cc := c.(*ApiContext)
 	limit, offset := GetPagination(c)
+	query, err := getSmesherQuery(c)
+	if err != nil {
+		return c.NoContent(http.StatusBadRequest)
+	}
 
-	if cached, err := cc.Cache.Get(context.Background(), fmt.Sprintf("smeshers-%d-%d", limit, offset),
+	if cached, err := cc.Cache.Get(context.Background(), fmt.Sprintf("smeshers-%d-%d-%s", limit, offset, query),
 		new(*storage.SmesherList)); err == nil {
 		return c.JSON(http.StatusOK, cached)
 	}
 
-	smeshers, err := cc.StorageClient.GetSmeshers(cc.Storage, uint64(limit), uint64(offset))
+	smeshers, err := cc.StorageClient.GetSmeshers(cc.Storage, uint64(limit), uint64(offset), query)
 	if err != nil {
 		log.Warning("failed to get smeshers: %v", err)
 		return c.NoContent(http.StatusInternalServerError)
 	}
 
-	if err = cc.Cache.Set(context.Background(), fmt.Sprintf("smeshers-%d-%d", limit, offset), smeshers); err != nil {
+	if err = cc.Cache.Set(context.Background(), fmt.Sprintf("smeshers-%d-%d-%s", limit, offset, query),
+		smeshers, smesherCacheOptions(query, limit, offset)...); err != nil {
 		log.Warning("failed to cache smeshers: %v", err)
 		return c.NoContent(http.StatusInternalServerError)
 	}
@@ -44,25 +99,27 @@ func SmeshersRefresh(c echo.Context) error {
 	cc := c.(*ApiContext)
 
 	go func() {
-		smeshers, err := cc.StorageClient.GetSmeshers(cc.Storage, 1000, 0)
-		if err != nil {
-			log.Warning("failed to get smeshers: %v", err)
-			return
-		}
-
-		for i := 0; i < len(smeshers.Smeshers); i += 20 {
-			end := i + 20
-			if end > len(smeshers.Smeshers) {
-				end = len(smeshers.Smeshers)
-			}
-			if err = cc.Cache.Set(
-				context.Background(),
-				fmt.Sprintf("smeshers-%d-%d", 20, i),
-				&storage.SmesherList{Smeshers: smeshers.Smeshers[i:end]},
-			); err != nil {
-				log.Warning("failed to cache smeshers: %v", err)
+		for _, query := range smesherRefreshQueries {
+			smeshers, err := cc.StorageClient.GetSmeshers(cc.Storage, 1000, 0, query)
+			if err != nil {
+				log.Warning("failed to get smeshers: %v", err)
 				return
 			}
+
+			for i := 0; i < len(smeshers.Smeshers); i += 20 {
+				end := i + 20
+				if end > len(smeshers.Smeshers) {
+					end = len(smeshers.Smeshers)
+				}
+				if err = cc.Cache.Set(
+					context.Background(),
+					fmt.Sprintf("smeshers-%d-%d-%s", 20, i, query),
+					&storage.SmesherList{Smeshers: smeshers.Smeshers[i:end]},
+				); err != nil {
+					log.Warning("failed to cache smeshers: %v", err)
+					return
+				}
+			}
 		}
 
 		log.Info("smeshers refreshed")
@@ -81,20 +138,27 @@ func SmeshersByEpoch(c echo.Context) error {
 	}
 
 	limit, offset := GetPagination(c)
+	query, err := getSmesherQuery(c)
+	if err != nil {
+		return c.NoContent(http.StatusBadRequest)
+	}
 
 	if cached, err := cc.Cache.Get(context.Background(),
-		fmt.Sprintf("smeshers-epoch-%d-%d-%d", epochId, limit, offset), new(storage.SmesherList)); err == nil {
+		fmt.Sprintf("smeshers-epoch-%d-%d-%d-%s", epochId, limit, offset, query),
+		new(storage.SmesherList)); err == nil {
 		return c.JSON(http.StatusOK, cached)
 	}
 
-	smeshers, err := cc.StorageClient.GetSmeshersByEpoch(cc.Storage, uint64(limit), uint64(offset), uint64(epochId))
+	smeshers, err := cc.StorageClient.GetSmeshersByEpoch(cc.Storage, uint64(limit), uint64(offset),
+		uint64(epochId), query)
 	if err != nil {
 		log.Warning("failed to get smeshers: %v", err)
 		return c.NoContent(http.StatusInternalServerError)
 	}
 
 	if err = cc.Cache.Set(context.Background(),
-		fmt.Sprintf("smeshers-epoch-%d-%d-%d", epochId, limit, offset), smeshers); err != nil {
+		fmt.Sprintf("smeshers-epoch-%d-%d-%d-%s", epochId, limit, offset, query), smeshers,
+		smesherCacheOptions(query, limit, offset)...); err != nil {
 		log.Warning("failed to cache smeshers: %v", err)
 		return c.NoContent(http.StatusInternalServerError)
 	}
@@ -114,25 +178,27 @@ func SmeshersByEpochRefresh(c echo.Context) error {
 	}
 
 	go func() {
-		smeshers, err := cc.StorageClient.GetSmeshersByEpoch(cc.Storage, 1000, 0, uint64(epochId))
-		if err != nil {
-			log.Warning("failed to get smeshers: %v", err)
-			return
-		}
-
-		for i := 0; i < len(smeshers.Smeshers); i += 20 {
-			end := i + 20
-			if end > len(smeshers.Smeshers) {
-				end = len(smeshers.Smeshers)
-			}
-			if err = cc.Cache.Set(
-				context.Background(),
-				fmt.Sprintf("smeshers-%d-%d", 20, i),
-				&storage.SmesherList{Smeshers: smeshers.Smeshers[i:end]},
-			); err != nil {
-				log.Warning("failed to cache smeshers: %v", err)
+		for _, query := range smesherRefreshQueries {
+			smeshers, err := cc.StorageClient.GetSmeshersByEpoch(cc.Storage, 1000, 0, uint64(epochId), query)
+			if err != nil {
+				log.Warning("failed to get smeshers: %v", err)
 				return
 			}
+
+			for i := 0; i < len(smeshers.Smeshers); i += 20 {
+				end := i + 20
+				if end > len(smeshers.Smeshers) {
+					end = len(smeshers.Smeshers)
+				}
+				if err = cc.Cache.Set(
+					context.Background(),
+					fmt.Sprintf("smeshers-epoch-%d-%d-%d-%s", epochId, 20, i, query),
+					&storage.SmesherList{Smeshers: smeshers.Smeshers[i:end]},
+				); err != nil {
+					log.Warning("failed to cache smeshers: %v", err)
+					return
+				}
+			}
 		}
 
 		log.Info("smeshers by epoch %d refreshed", epochId)
@@ -154,7 +220,7 @@ func Smesher(c echo.Context) error {
 
 	smesher, err := cc.StorageClient.GetSmesher(cc.Storage, hash.Bytes())
 	if err != nil {
-		if err.Error() == "smesher not found" {
+		if errors.Is(err, storage.ErrSmesherNotFound) {
 			return c.NoContent(http.StatusNotFound)
 		}
 
@@ -170,3 +236,124 @@ func Smesher(c echo.Context) error {
 
 	return c.JSON(http.StatusOK, smesher)
 }
+
+func SmesherEpochs(c echo.Context) error {
+	cc := c.(*ApiContext)
+
+	smesherId := c.Param("smesherId")
+	hash := types.HexToHash32(smesherId)
+
+	if cached, err := cc.Cache.Get(context.Background(), "smesherEpochs-"+smesherId,
+		new(*storage.SmesherEpochs)); err == nil {
+		return c.JSON(http.StatusOK, cached)
+	}
+
+	epochs, err := cc.StorageClient.GetSmesherEpochs(cc.Storage, hash.Bytes(), cc.LayersPerEpoch)
+	if err != nil {
+		if errors.Is(err, storage.ErrSmesherNotFound) {
+			return c.NoContent(http.StatusNotFound)
+		}
+
+		log.Warning("failed to get smesher epochs: %v", err)
+		return c.NoContent(http.StatusInternalServerError)
+	}
+
+	if err = cc.Cache.Set(context.Background(), "smesherEpochs-"+smesherId, epochs,
+		store.WithExpiration(cache.ShortExpiration)); err != nil {
+		log.Warning("failed to cache smesher epochs: %v", err)
+		return c.NoContent(http.StatusInternalServerError)
+	}
+
+	return c.JSON(http.StatusOK, epochs)
+}
+
+func SmesherYield(c echo.Context) error {
+	cc := c.(*ApiContext)
+
+	smesherId := c.Param("smesherId")
+	hash := types.HexToHash32(smesherId)
+
+	// last finished epoch by default
+	epoch := cc.StorageClient.CurrentEpoch(cc.LayersPerEpoch) - 1
+	if v := c.QueryParam("epoch"); v != "" {
+		var err error
+		epoch, err = strconv.ParseInt(v, 10, 32)
+		if err != nil {
+			return c.NoContent(http.StatusBadRequest)
+		}
+	}
+	if epoch < 0 {
+		return c.NoContent(http.StatusBadRequest)
+	}
+
+	// yield is annualised from a whole epoch of rewards, so only completed epochs are accepted
+	if epoch >= cc.StorageClient.CurrentEpoch(cc.LayersPerEpoch) {
+		return c.NoContent(http.StatusBadRequest)
+	}
+
+	key := fmt.Sprintf("smesherYield-%s-%d", smesherId, epoch)
+	if cached, err := cc.Cache.Get(context.Background(), key, new(*storage.Yield)); err == nil {
+		return c.JSON(http.StatusOK, cached)
+	}
+
+	yield, err := cc.StorageClient.GetSmesherYield(cc.Storage, hash.Bytes(), epoch, cc.LayersPerEpoch)
+	if err != nil {
+		if errors.Is(err, storage.ErrSmesherNotFound) {
+			return c.NoContent(http.StatusNotFound)
+		}
+
+		log.Warning("failed to get smesher yield: %v", err)
+		return c.NoContent(http.StatusInternalServerError)
+	}
+
+	if err = cc.Cache.Set(context.Background(), key, yield,
+		store.WithExpiration(cache.ShortExpiration)); err != nil {
+		log.Warning("failed to cache smesher yield: %v", err)
+		return c.NoContent(http.StatusInternalServerError)
+	}
+
+	return c.JSON(http.StatusOK, yield)
+}
+
+func SmesherPerformance(c echo.Context) error {
+	cc := c.(*ApiContext)
+
+	smesherId := c.Param("smesherId")
+	hash := types.HexToHash32(smesherId)
+
+	// last finished epoch by default
+	epoch := cc.StorageClient.CurrentEpoch(cc.LayersPerEpoch) - 1
+	if v := c.QueryParam("epoch"); v != "" {
+		var err error
+		epoch, err = strconv.ParseInt(v, 10, 32)
+		if err != nil {
+			return c.NoContent(http.StatusBadRequest)
+		}
+	}
+	if epoch < 0 {
+		return c.NoContent(http.StatusBadRequest)
+	}
+
+	key := fmt.Sprintf("smesherPerformance-%s-%d", smesherId, epoch)
+	if cached, err := cc.Cache.Get(context.Background(), key, new(*storage.SmesherPerformance)); err == nil {
+		return c.JSON(http.StatusOK, cached)
+	}
+
+	performance, err := cc.StorageClient.GetSmesherPerformance(cc.Storage, hash.Bytes(), epoch, cc.LayersPerEpoch)
+	if err != nil {
+		if errors.Is(err, storage.ErrSmesherNotFound) {
+			return c.NoContent(http.StatusNotFound)
+		}
+
+		log.Warning("failed to get smesher performance: %v", err)
+		return c.NoContent(http.StatusInternalServerError)
+	}
+
+	if err = cc.Cache.Set(context.Background(), key, performance,
+		store.WithExpiration(cache.ShortExpiration)); err != nil {
+		log.Warning("failed to cache smesher performance: %v", err)
+		return c.NoContent(http.StatusInternalServerError)
+	}
+
+	return c.JSON(http.StatusOK, performance)
+}
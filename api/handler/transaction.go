@@ -0,0 +1,66 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/eko/gocache/lib/v4/store"
+	"github.com/labstack/echo/v4"
+	"github.com/spacemeshos/go-spacemesh/log"
+
+	"github.com/spacemeshos/explorer-backend/api/cache"
+	"github.com/spacemeshos/explorer-backend/api/storage"
+)
+
+func Transactions(c echo.Context) error {
+	cc := c.(*ApiContext)
+	limit, offset := GetPagination(c)
+
+	if cached, err := cc.Cache.Get(context.Background(), fmt.Sprintf("transactions-%d-%d", limit, offset),
+		new(*storage.TransactionList)); err == nil {
+		return c.JSON(http.StatusOK, cached)
+	}
+
+	txs, err := cc.StorageClient.GetTransactions(cc.Storage, uint64(limit), uint64(offset))
+	if err != nil {
+		log.Warning("failed to get transactions: %v", err)
+		return c.NoContent(http.StatusInternalServerError)
+	}
+
+	if err = cc.Cache.Set(context.Background(), fmt.Sprintf("transactions-%d-%d", limit, offset), txs,
+		store.WithExpiration(cache.ShortExpiration)); err != nil {
+		log.Warning("failed to cache transactions: %v", err)
+		return c.NoContent(http.StatusInternalServerError)
+	}
+
+	return c.JSON(http.StatusOK, txs)
+}
+
+func TransactionTypes(c echo.Context) error {
+	cc := c.(*ApiContext)
+
+	from, to, err := GetEpochRange(c, "from_epoch", "to_epoch")
+	if err != nil {
+		return c.NoContent(http.StatusBadRequest)
+	}
+
+	key := fmt.Sprintf("transactionTypes-%d-%d", from, to)
+	if cached, err := cc.Cache.Get(context.Background(), key, new(*storage.TransactionTypes)); err == nil {
+		return c.JSON(http.StatusOK, cached)
+	}
+
+	txTypes, err := cc.StorageClient.GetTransactionTypes(cc.Storage, from, to, cc.LayersPerEpoch)
+	if err != nil {
+		log.Warning("failed to get transaction types: %v", err)
+		return c.NoContent(http.StatusInternalServerError)
+	}
+
+	if err = cc.Cache.Set(context.Background(), key, txTypes,
+		store.WithExpiration(cache.ShortExpiration)); err != nil {
+		log.Warning("failed to cache transaction types: %v", err)
+		return c.NoContent(http.StatusInternalServerError)
+	}
+
+	return c.JSON(http.StatusOK, txTypes)
+}
@@ -0,0 +1,72 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/eko/gocache/lib/v4/store"
+	"github.com/labstack/echo/v4"
+	"github.com/spacemeshos/go-spacemesh/common/types"
+	"github.com/spacemeshos/go-spacemesh/log"
+
+	"github.com/spacemeshos/explorer-backend/api/cache"
+	"github.com/spacemeshos/explorer-backend/api/storage"
+)
+
+func Vaults(c echo.Context) error {
+	cc := c.(*ApiContext)
+	limit, offset := GetPagination(c)
+
+	key := fmt.Sprintf("vaults-%d-%d", limit, offset)
+	if cached, err := cc.Cache.Get(context.Background(), key, new(*storage.VaultList)); err == nil {
+		return c.JSON(http.StatusOK, cached)
+	}
+
+	vaults, err := cc.StorageClient.GetVaults(cc.Storage, uint64(limit), uint64(offset))
+	if err != nil {
+		log.Warning("failed to get vaults: %v", err)
+		return c.NoContent(http.StatusInternalServerError)
+	}
+
+	if err = cc.Cache.Set(context.Background(), key, vaults,
+		store.WithExpiration(cache.ShortExpiration)); err != nil {
+		log.Warning("failed to cache vaults: %v", err)
+		return c.NoContent(http.StatusInternalServerError)
+	}
+
+	return c.JSON(http.StatusOK, vaults)
+}
+
+func Vault(c echo.Context) error {
+	cc := c.(*ApiContext)
+
+	address := c.Param("address")
+	addr, err := types.StringToAddress(address)
+	if err != nil {
+		log.Warning("failed to parse vault address: %v", err)
+		return c.NoContent(http.StatusBadRequest)
+	}
+
+	if cached, err := cc.Cache.Get(context.Background(), "vault"+address, new(*storage.Vault)); err == nil {
+		return c.JSON(http.StatusOK, cached)
+	}
+
+	vault, err := cc.StorageClient.GetVault(cc.Storage, addr)
+	if err != nil {
+		if errors.Is(err, storage.ErrVaultNotFound) {
+			return c.NoContent(http.StatusNotFound)
+		}
+		log.Warning("failed to get vault: %v", err)
+		return c.NoContent(http.StatusInternalServerError)
+	}
+
+	if err = cc.Cache.Set(context.Background(), "vault"+address, vault,
+		store.WithExpiration(cache.ShortExpiration)); err != nil {
+		log.Warning("failed to cache vault: %v", err)
+		return c.NoContent(http.StatusInternalServerError)
+	}
+
+	return c.JSON(http.StatusOK, vault)
+}
@@ -10,14 +10,41 @@ import (
 func Router(e *echo.Echo) {
 	e.Use(echoprometheus.NewMiddleware("spacemesh_explorer_stats_api"))
 	e.GET("/layer/:id", handler.Layer)
+	e.GET("/layer/:id/transactions", handler.LayerTransactions)
+	e.GET("/layers", handler.Layers)
 	e.GET("/epoch/:id", handler.Epoch)
+	e.GET("/epochs", handler.Epochs)
 	e.GET("/epoch/:id/decentral", handler.EpochDecentral)
+	e.GET("/epoch/:id/transactions", handler.EpochTransactions)
+	e.GET("/epoch/:id/yield", handler.EpochYield)
+	e.GET("/epoch/:id/atx-timing", handler.EpochAtxTiming)
 	e.GET("/account/:address", handler.Account)
+	e.GET("/account/:address/transactions", handler.AccountTransactions)
+	e.GET("/account/:address/balance", handler.AccountBalance)
+	e.GET("/account/:address/balance-history", handler.AccountBalanceHistory)
+	e.GET("/account/:address/smeshers", handler.AccountSmeshers)
+	e.GET("/accounts/top", handler.AccountsTop)
+	e.GET("/accounts/distribution", handler.AccountsDistribution)
+	e.GET("/accounts/growth", handler.AccountsGrowth)
 	e.GET("/smeshers/:epoch", handler.SmeshersByEpoch)
 	e.GET("/smeshers", handler.Smeshers)
 	e.GET("/smesher/:smesherId", handler.Smesher)
+	e.GET("/smesher/:smesherId/epochs", handler.SmesherEpochs)
+	e.GET("/smesher/:smesherId/yield", handler.SmesherYield)
+	e.GET("/smesher/:smesherId/performance", handler.SmesherPerformance)
 	e.GET("/overview", handler.Overview)
 	e.GET("/circulation", handler.Circulation)
+	e.GET("/circulation/history", handler.CirculationHistory)
+	e.GET("/supply", handler.Supply)
+	e.GET("/emission/projection", handler.EmissionProjection)
+	e.GET("/audit/issuance", handler.IssuanceAudit)
+	e.GET("/network/capacity", handler.NetworkCapacity)
+	e.GET("/malfeasance", handler.Malfeasance)
+	e.GET("/transactions", handler.Transactions)
+	e.GET("/transactions/types", handler.TransactionTypes)
+	e.GET("/rewards/composition", handler.RewardsComposition)
+	e.GET("/vaults", handler.Vaults)
+	e.GET("/vault/:address", handler.Vault)
 }
 
 func RefreshRouter(e *echo.Echo) {
@@ -29,4 +56,6 @@ func RefreshRouter(e *echo.Echo) {
 	g.GET("/smeshers/:epoch", handler.SmeshersByEpochRefresh)
 	g.GET("/smeshers", handler.SmeshersRefresh)
 	g.GET("/circulation", handler.CirculationRefresh)
+	g.GET("/accounts/top", handler.AccountsTopRefresh)
+	g.GET("/accounts/distribution", handler.AccountsDistributionRefresh)
 }
@@ -1,6 +1,12 @@
 package storage
 
 import (
+	"encoding/hex"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
 	"github.com/spacemeshos/go-spacemesh/common/types"
 	"github.com/spacemeshos/go-spacemesh/sql"
 	"github.com/spacemeshos/go-spacemesh/sql/builder"
@@ -26,6 +32,7 @@ type AccountStats struct {
 	TransactionsCount uint64 `json:"transactions_count"`
 	RewardsCount      uint64 `json:"rewards_count"`
 	RewardsSum        uint64 `json:"rewards_sum"`
+	SmeshersCount     uint64 `json:"smeshers_count"`
 }
 
 func (c *Client) GetAccountsStats(db sql.Executor, addr types.Address) (*AccountStats, error) {
@@ -36,26 +43,11 @@ func (c *Client) GetAccountsStats(db sql.Executor, addr types.Address) (*Account
 		TransactionsCount: 0,
 		RewardsCount:      0,
 		RewardsSum:        0,
+		SmeshersCount:     0,
 	}
 
 	ops := builder.Operations{
-		Filter: []builder.Op{
-			{
-				Group: []builder.Op{
-					{
-						Field: builder.Address,
-						Token: builder.Eq,
-						Value: addr.Bytes(),
-					},
-					{
-						Field: builder.Principal,
-						Token: builder.Eq,
-						Value: addr.Bytes(),
-					},
-				},
-				GroupOperator: builder.Or,
-			},
-		},
+		Filter: accountTransactionsFilter(addr),
 	}
 	err := transactions.IterateTransactionsOps(db, ops, func(tx *types.MeshTransaction,
 		result *types.TransactionResult,
@@ -66,9 +58,11 @@ func (c *Client) GetAccountsStats(db sql.Executor, addr types.Address) (*Account
 		}
 
 		if contents.GetSend() != nil {
+			// a send to self is counted both ways
 			if contents.GetSend().GetDestination() == addr.String() {
 				stats.Received += contents.GetSend().GetAmount()
-			} else {
+			}
+			if tx.TxHeader != nil && tx.Principal == addr {
 				stats.Sent += contents.GetSend().GetAmount()
 			}
 		}
@@ -87,5 +81,427 @@ func (c *Client) GetAccountsStats(db sql.Executor, addr types.Address) (*Account
 	stats.RewardsSum = sum
 	stats.RewardsCount = count
 
+	smeshersCount, err := c.GetSmeshersByCoinbaseCount(db, addr)
+	if err != nil {
+		return nil, err
+	}
+	stats.SmeshersCount = smeshersCount
+
 	return stats, nil
 }
+
+const (
+	DirectionIncoming = "incoming"
+	DirectionOutgoing = "outgoing"
+	DirectionSelf     = "self"
+)
+
+type AccountTransactionList struct {
+	Transactions []AccountTransaction `json:"transactions"`
+	Cursor       string               `json:"cursor,omitempty"`
+}
+
+type AccountTransaction struct {
+	Id           string `json:"id"`
+	Layer        uint32 `json:"layer"`
+	Direction    string `json:"direction"`
+	Counterparty string `json:"counterparty,omitempty"`
+	Amount       uint64 `json:"amount"`
+	Fee          uint64 `json:"fee"`
+	Type         string `json:"type"`
+	Status       string `json:"status"`
+}
+
+// TxCursor points at the last transaction of a page, pages are ordered by layer desc, id asc.
+type TxCursor struct {
+	Layer int64
+	Id    types.TransactionID
+}
+
+func (c TxCursor) String() string {
+	return fmt.Sprintf("%d:%s", c.Layer, c.Id.String())
+}
+
+func ParseTxCursor(cursor string) (*TxCursor, error) {
+	layer, id, ok := strings.Cut(cursor, ":")
+	if !ok {
+		return nil, fmt.Errorf("malformed cursor %q", cursor)
+	}
+	lid, err := strconv.ParseInt(layer, 10, 64)
+	if err != nil || lid < 0 {
+		return nil, fmt.Errorf("malformed cursor layer %q", layer)
+	}
+	var txId types.TransactionID
+	if len(id) != hex.EncodedLen(len(txId)) {
+		return nil, fmt.Errorf("malformed cursor id %q", id)
+	}
+	if _, err := hex.Decode(txId[:], []byte(id)); err != nil {
+		return nil, fmt.Errorf("malformed cursor id %q", id)
+	}
+	return &TxCursor{
+		Layer: lid,
+		Id:    txId,
+	}, nil
+}
+
+func accountTransactionsFilter(addr types.Address) []builder.Op {
+	return []builder.Op{
+		{
+			CustomQuery: "(id IN (SELECT tid FROM transactions_results_addresses WHERE address = ?1)" +
+				" OR principal = ?1)",
+			Value: addr.Bytes(),
+		},
+	}
+}
+
+func (c *Client) GetAccountTransactions(db sql.Executor, addr types.Address, limit uint64,
+	cursor *TxCursor,
+) (*AccountTransactionList, error) {
+	txList := &AccountTransactionList{
+		Transactions: []AccountTransaction{},
+	}
+
+	filter := accountTransactionsFilter(addr)
+	if cursor != nil {
+		// layer <= ?2 and (layer < ?2 or id > ?3) is the same as
+		// layer < ?2 or (layer = ?2 and id > ?3), but binds one value per op.
+		filter = append(filter,
+			builder.Op{
+				CustomQuery: "layer <= ?2",
+				Value:       cursor.Layer,
+			},
+			builder.Op{
+				CustomQuery: "(layer < ?2 OR id > ?3)",
+				Value:       cursor.Id.Bytes(),
+			},
+		)
+	}
+	ops := builder.Operations{
+		Filter: filter,
+		Modifiers: []builder.Modifier{
+			{
+				Key:   builder.OrderBy,
+				Value: "layer DESC, id",
+			},
+			{
+				Key:   builder.Limit,
+				Value: int64(limit),
+			},
+		},
+	}
+
+	var last *types.MeshTransaction
+	err := transactions.IterateTransactionsOps(db, ops, func(tx *types.MeshTransaction,
+		result *types.TransactionResult,
+	) bool {
+		txList.Transactions = append(txList.Transactions, toAccountTransaction(addr, tx, result))
+		last = tx
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if last != nil && uint64(len(txList.Transactions)) == limit {
+		txList.Cursor = TxCursor{
+			Layer: int64(last.LayerID.Uint32()),
+			Id:    last.ID,
+		}.String()
+	}
+
+	return txList, nil
+}
+
+func toAccountTransaction(addr types.Address, tx *types.MeshTransaction,
+	result *types.TransactionResult,
+) AccountTransaction {
+	t := toTransaction(tx, result)
+	accTx := AccountTransaction{
+		Id:     t.Id,
+		Layer:  t.Layer,
+		Fee:    t.Fee,
+		Type:   t.Type,
+		Status: t.Status,
+	}
+
+	var source, destination string
+	contents, _, err := toTxContents(tx.Raw)
+	if err == nil {
+		switch {
+		case contents.GetSend() != nil:
+			source = t.Principal
+			destination = contents.GetSend().GetDestination()
+			accTx.Amount = contents.GetSend().GetAmount()
+		case contents.GetDrainVault() != nil:
+			source = contents.GetDrainVault().GetVault()
+			destination = contents.GetDrainVault().GetDestination()
+			accTx.Amount = contents.GetDrainVault().GetAmount()
+		default:
+			source = t.Principal
+		}
+	}
+
+	switch {
+	case source == addr.String() && destination == addr.String():
+		accTx.Direction = DirectionSelf
+	case destination == addr.String():
+		accTx.Direction = DirectionIncoming
+		accTx.Counterparty = source
+	default:
+		accTx.Direction = DirectionOutgoing
+		accTx.Counterparty = destination
+	}
+
+	return accTx
+}
+
+const (
+	BucketLayer = "layer"
+	BucketEpoch = "epoch"
+)
+
+type AccountBalance struct {
+	Account      string `json:"account"`
+	Layer        uint32 `json:"layer"`
+	LayerUpdated uint32 `json:"layer_updated"`
+	Balance      uint64 `json:"balance"`
+}
+
+type BalanceHistory struct {
+	Account string         `json:"account"`
+	Bucket  string         `json:"bucket"`
+	Points  []BalancePoint `json:"points"`
+}
+
+type BalancePoint struct {
+	Epoch   uint32 `json:"epoch"`
+	Layer   uint32 `json:"layer"`
+	Balance uint64 `json:"balance"`
+}
+
+// GetAccountBalance returns the balance of the account as of the given layer.
+// Negative layer means the current layer.
+func (c *Client) GetAccountBalance(db sql.Executor, addr types.Address, layer int64) (*AccountBalance, error) {
+	if layer < 0 {
+		layer = int64(c.NodeClock.CurrentLayer().Uint32())
+	}
+	balance := &AccountBalance{
+		Account: addr.String(),
+		Layer:   uint32(layer),
+	}
+
+	_, err := db.Exec(`SELECT balance, layer_updated FROM accounts
+                                WHERE address = ?1 AND layer_updated <= ?2
+                                ORDER BY layer_updated DESC LIMIT 1`,
+		func(stmt *sql.Statement) {
+			stmt.BindBytes(1, addr.Bytes())
+			stmt.BindInt64(2, layer)
+		},
+		func(stmt *sql.Statement) bool {
+			balance.Balance = uint64(stmt.ColumnInt64(0))
+			balance.LayerUpdated = uint32(stmt.ColumnInt64(1))
+			return true
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	return balance, nil
+}
+
+// GetAccountBalanceHistory rebuilds the balance curve of the account from the accounts table.
+// from and to are layers for BucketLayer and epochs for BucketEpoch, both inclusive.
+// BucketLayer returns the balance at from and every layer the balance was updated at,
+// BucketEpoch returns the closing balance of every epoch. Negative to means up to now.
+func (c *Client) GetAccountBalanceHistory(db sql.Executor, addr types.Address, from, to, layersPerEpoch int64,
+	bucket string,
+) (*BalanceHistory, error) {
+	if to < 0 {
+		to = int64(c.NodeClock.CurrentLayer().Uint32())
+		if bucket == BucketEpoch {
+			to /= layersPerEpoch
+		}
+	}
+	startLayer, endLayer := from, to
+	if bucket == BucketEpoch {
+		startLayer = from * layersPerEpoch
+		endLayer = (to+1)*layersPerEpoch - 1
+	}
+
+	opening, err := c.GetAccountBalance(db, addr, startLayer)
+	if err != nil {
+		return nil, err
+	}
+
+	history := &BalanceHistory{
+		Account: addr.String(),
+		Bucket:  bucket,
+		Points:  []BalancePoint{},
+	}
+
+	balance := opening.Balance
+	epoch := from
+	if bucket == BucketLayer {
+		history.Points = append(history.Points, BalancePoint{
+			Epoch:   uint32(startLayer / layersPerEpoch),
+			Layer:   uint32(startLayer),
+			Balance: balance,
+		})
+	}
+	appendEpochs := func(until int64) {
+		for ; epoch < until; epoch++ {
+			history.Points = append(history.Points, BalancePoint{
+				Epoch:   uint32(epoch),
+				Layer:   uint32((epoch+1)*layersPerEpoch - 1),
+				Balance: balance,
+			})
+		}
+	}
+
+	_, err = db.Exec(`SELECT balance, layer_updated FROM accounts
+                                WHERE address = ?1 AND layer_updated > ?2 AND layer_updated <= ?3
+                                ORDER BY layer_updated ASC`,
+		func(stmt *sql.Statement) {
+			stmt.BindBytes(1, addr.Bytes())
+			stmt.BindInt64(2, startLayer)
+			stmt.BindInt64(3, endLayer)
+		},
+		func(stmt *sql.Statement) bool {
+			layer := stmt.ColumnInt64(1)
+			if bucket == BucketEpoch {
+				appendEpochs(layer / layersPerEpoch)
+				balance = uint64(stmt.ColumnInt64(0))
+				return true
+			}
+			balance = uint64(stmt.ColumnInt64(0))
+			history.Points = append(history.Points, BalancePoint{
+				Epoch:   uint32(layer / layersPerEpoch),
+				Layer:   uint32(layer),
+				Balance: balance,
+			})
+			return true
+		})
+	if err != nil {
+		return nil, err
+	}
+	if bucket == BucketEpoch {
+		appendEpochs(to + 1)
+	}
+
+	return history, nil
+}
+
+type AccountList struct {
+	Accounts []AccountBalance `json:"accounts"`
+}
+
+type AccountsDistribution struct {
+	AccountsCount uint64          `json:"accounts_count"`
+	BalanceSum    uint64          `json:"balance_sum"`
+	Buckets       []BalanceBucket `json:"buckets"`
+}
+
+// BalanceBucket holds accounts with Min <= balance < Max, balances in smidge.
+type BalanceBucket struct {
+	Min           uint64  `json:"min"`
+	Max           uint64  `json:"max"`
+	AccountsCount uint64  `json:"accounts_count"`
+	BalanceSum    uint64  `json:"balance_sum"`
+	SupplyShare   float64 `json:"supply_share"`
+}
+
+// latestBalancesQuery selects the latest balance of every account as of layer ?1.
+// SQLite takes bare columns from the row holding MAX(layer_updated).
+const latestBalancesQuery = `SELECT address, balance, MAX(layer_updated) AS layer_updated
+                                FROM accounts WHERE layer_updated <= ?1 GROUP BY address`
+
+// GetTopAccounts ranks accounts by balance at the end of atEpoch, negative atEpoch means latest.
+func (c *Client) GetTopAccounts(db sql.Executor, limit, offset uint64, atEpoch, layersPerEpoch int64,
+) (*AccountList, error) {
+	layer := int64(c.NodeClock.CurrentLayer().Uint32())
+	if atEpoch >= 0 {
+		layer = (atEpoch+1)*layersPerEpoch - 1
+	}
+
+	accountList := &AccountList{
+		Accounts: []AccountBalance{},
+	}
+	_, err := db.Exec(`SELECT address, balance, layer_updated FROM (`+latestBalancesQuery+`)
+                                ORDER BY balance DESC, address ASC LIMIT ?2 OFFSET ?3`,
+		func(stmt *sql.Statement) {
+			stmt.BindInt64(1, layer)
+			stmt.BindInt64(2, int64(limit))
+			stmt.BindInt64(3, int64(offset))
+		},
+		func(stmt *sql.Statement) bool {
+			var addr types.Address
+			stmt.ColumnBytes(0, addr[:])
+			accountList.Accounts = append(accountList.Accounts, AccountBalance{
+				Account:      addr.String(),
+				Layer:        uint32(layer),
+				Balance:      uint64(stmt.ColumnInt64(1)),
+				LayerUpdated: uint32(stmt.ColumnInt64(2)),
+			})
+			return true
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	return accountList, nil
+}
+
+// GetAccountsDistribution buckets latest balances by order of magnitude, zero balances get their own bucket.
+func (c *Client) GetAccountsDistribution(db sql.Executor) (*AccountsDistribution, error) {
+	distribution := &AccountsDistribution{
+		Buckets: []BalanceBucket{},
+	}
+
+	_, err := db.Exec(`SELECT CASE WHEN balance = 0 THEN -1 ELSE LENGTH(CAST(balance AS TEXT)) - 1 END AS magnitude,
+                                COUNT(*), SUM(balance)
+                                FROM (`+latestBalancesQuery+`)
+                                GROUP BY magnitude ORDER BY magnitude ASC`,
+		func(stmt *sql.Statement) {
+			stmt.BindInt64(1, math.MaxInt64)
+		},
+		func(stmt *sql.Statement) bool {
+			bucket := BalanceBucket{
+				AccountsCount: uint64(stmt.ColumnInt64(1)),
+				BalanceSum:    uint64(stmt.ColumnInt64(2)),
+			}
+			if magnitude := stmt.ColumnInt64(0); magnitude < 0 {
+				bucket.Max = 1
+			} else {
+				bucket.Min = pow10(magnitude)
+				bucket.Max = pow10(magnitude + 1)
+			}
+			distribution.AccountsCount += bucket.AccountsCount
+			distribution.BalanceSum += bucket.BalanceSum
+			distribution.Buckets = append(distribution.Buckets, bucket)
+			return true
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	if distribution.BalanceSum > 0 {
+		for i := range distribution.Buckets {
+			distribution.Buckets[i].SupplyShare = float64(distribution.Buckets[i].BalanceSum) /
+				float64(distribution.BalanceSum)
+		}
+	}
+
+	return distribution, nil
+}
+
+// pow10 saturates at math.MaxUint64 as 10^20 overflows.
+func pow10(n int64) uint64 {
+	v := uint64(1)
+	for ; n > 0; n-- {
+		if v > math.MaxUint64/10 {
+			return math.MaxUint64
+		}
+		v *= 10
+	}
+	return v
+}
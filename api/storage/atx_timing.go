@@ -0,0 +1,89 @@
+package storage
+
+import (
+	"time"
+
+	"github.com/spacemeshos/go-spacemesh/common/types"
+	"github.com/spacemeshos/go-spacemesh/sql"
+)
+
+// atxTimingBuckets is the number of histogram buckets between the PoET round end and the epoch start.
+const atxTimingBuckets = 24
+
+// AtxTiming is a histogram of when atxs targeting an epoch were received.
+// Buckets span from the PoET round end to the epoch start, atxs received later are counted after the range.
+type AtxTiming struct {
+	Epoch               uint32            `json:"epoch"`
+	PoetRoundEnd        int64             `json:"poet_round_end"`
+	EpochStart          int64             `json:"epoch_start"`
+	AtxsCount           uint64            `json:"atxs_count"`
+	BeforeRoundEndCount uint64            `json:"before_round_end_count"`
+	AfterRangeCount     uint64            `json:"after_range_count"`
+	LateCount           uint64            `json:"late_count"`
+	LateShare           float64           `json:"late_share"`
+	Buckets             []AtxTimingBucket `json:"buckets"`
+}
+
+type AtxTimingBucket struct {
+	Start           int64  `json:"start"`
+	End             int64  `json:"end"`
+	SinceRoundEnd   int64  `json:"since_round_end"`
+	SinceEpochStart int64  `json:"since_epoch_start"`
+	AtxsCount       uint64 `json:"atxs_count"`
+}
+
+// GetAtxTiming returns arrival times of atxs published in the epoch before epoch.
+// As in the node, the PoET round of the publish epoch ends phase shift minus cycle gap after its start.
+// An atx is late if it was received after the epoch it targets started.
+func (c *Client) GetAtxTiming(db sql.Executor, epoch, layersPerEpoch int64) (*AtxTiming, error) {
+	publishStart := c.NodeClock.LayerToTime(types.LayerID((epoch - 1) * layersPerEpoch))
+	epochStart := c.NodeClock.LayerToTime(types.LayerID(epoch * layersPerEpoch))
+	roundEnd := publishStart.Add(c.PoetPhaseShift - c.PoetCycleGap)
+	width := max(epochStart.Sub(roundEnd)/atxTimingBuckets, time.Second)
+	rangeEnd := epochStart
+
+	timing := &AtxTiming{
+		Epoch:        uint32(epoch),
+		PoetRoundEnd: roundEnd.Unix(),
+		EpochStart:   epochStart.Unix(),
+		Buckets:      []AtxTimingBucket{},
+	}
+	for start := roundEnd; start.Before(rangeEnd); start = start.Add(width) {
+		timing.Buckets = append(timing.Buckets, AtxTimingBucket{
+			Start:           start.Unix(),
+			End:             start.Add(width).Unix(),
+			SinceRoundEnd:   int64(start.Sub(roundEnd).Seconds()),
+			SinceEpochStart: int64(start.Sub(epochStart).Seconds()),
+		})
+	}
+
+	_, err := db.Exec(`SELECT received FROM atxs WHERE epoch = ?1`,
+		func(stmt *sql.Statement) {
+			stmt.BindInt64(1, epoch-1)
+		},
+		func(stmt *sql.Statement) bool {
+			received := time.Unix(0, stmt.ColumnInt64(0))
+			timing.AtxsCount++
+			if received.After(epochStart) {
+				timing.LateCount++
+			}
+			switch {
+			case received.Before(roundEnd):
+				timing.BeforeRoundEndCount++
+			case !received.Before(rangeEnd):
+				timing.AfterRangeCount++
+			default:
+				timing.Buckets[received.Sub(roundEnd)/width].AtxsCount++
+			}
+			return true
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	if timing.AtxsCount > 0 {
+		timing.LateShare = float64(timing.LateCount) / float64(timing.AtxsCount)
+	}
+
+	return timing, nil
+}
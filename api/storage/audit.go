@@ -0,0 +1,101 @@
+package storage
+
+import (
+	"github.com/spacemeshos/economics/rewards"
+	"github.com/spacemeshos/go-spacemesh/sql"
+)
+
+const (
+	IssuanceOk         = "ok"
+	IssuanceMissing    = "missing"
+	IssuancePartial    = "partial"
+	IssuanceUnexpected = "unexpected"
+)
+
+type IssuanceAudit struct {
+	Epoch           uint32          `json:"epoch"`
+	ExpectedSum     uint64          `json:"expected_sum"`
+	ActualSum       uint64          `json:"actual_sum"`
+	MissingCount    uint64          `json:"missing_count"`
+	PartialCount    uint64          `json:"partial_count"`
+	UnexpectedCount uint64          `json:"unexpected_count"`
+	Layers          []LayerIssuance `json:"layers"`
+}
+
+// LayerIssuance compares the subsidy paid in a layer with the one defined by the economics package.
+type LayerIssuance struct {
+	Layer        uint32 `json:"layer"`
+	Expected     uint64 `json:"expected"`
+	Actual       uint64 `json:"actual"`
+	RewardsCount uint64 `json:"rewards_count"`
+	Status       string `json:"status"`
+}
+
+// GetIssuanceAudit audits the subsidy of every applied layer of epoch, rewards of later layers are not paid yet.
+// Each reward's share of the subsidy is rounded down, so a layer may fall short by up to one smidge per reward.
+func (c *Client) GetIssuanceAudit(db sql.Executor, epoch, layersPerEpoch int64) (*IssuanceAudit, error) {
+	lastApplied, err := c.GetLastAppliedLayer(db)
+	if err != nil {
+		return nil, err
+	}
+	start := epoch * layersPerEpoch
+	end := min(start+layersPerEpoch-1, lastApplied)
+	audit := &IssuanceAudit{
+		Epoch:  uint32(epoch),
+		Layers: make([]LayerIssuance, 0, max(end-start+1, 0)),
+	}
+	for lid := start; lid <= end; lid++ {
+		audit.Layers = append(audit.Layers, LayerIssuance{
+			Layer:    uint32(lid),
+			Expected: expectedSubsidyAtLayer(lid, layersPerEpoch),
+		})
+	}
+
+	_, err = db.Exec(`SELECT layer, COUNT(*), SUM(layer_reward) FROM rewards
+                                WHERE layer >= ?1 AND layer <= ?2 GROUP BY layer`,
+		func(stmt *sql.Statement) {
+			stmt.BindInt64(1, start)
+			stmt.BindInt64(2, end)
+		},
+		func(stmt *sql.Statement) bool {
+			if lid := stmt.ColumnInt64(0); lid >= start && lid <= end {
+				layer := &audit.Layers[lid-start]
+				layer.RewardsCount = uint64(stmt.ColumnInt64(1))
+				layer.Actual = uint64(stmt.ColumnInt64(2))
+			}
+			return true
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range audit.Layers {
+		layer := &audit.Layers[i]
+		switch {
+		case layer.Actual > layer.Expected || layer.Expected == 0 && layer.RewardsCount > 0:
+			layer.Status = IssuanceUnexpected
+			audit.UnexpectedCount++
+		case layer.Expected > 0 && layer.RewardsCount == 0:
+			layer.Status = IssuanceMissing
+			audit.MissingCount++
+		case layer.Actual+layer.RewardsCount < layer.Expected:
+			layer.Status = IssuancePartial
+			audit.PartialCount++
+		default:
+			layer.Status = IssuanceOk
+		}
+		audit.ExpectedSum += layer.Expected
+		audit.ActualSum += layer.Actual
+	}
+
+	return audit, nil
+}
+
+// expectedSubsidyAtLayer is the subsidy the protocol pays in layer.
+func expectedSubsidyAtLayer(layer, layersPerEpoch int64) uint64 {
+	effectiveGenesis := layersPerEpoch*2 - 1
+	if layer < effectiveGenesis {
+		return 0
+	}
+	return rewards.TotalSubsidyAtLayer(uint32(layer - effectiveGenesis))
+}
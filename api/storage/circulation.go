@@ -7,24 +7,106 @@ import (
 )
 
 type Circulation struct {
+	Layer       uint32 `json:"layer"`
 	Circulation uint64 `json:"circulation"`
 }
 
 func (c *Client) GetCirculation(db sql.Executor) (*Circulation, error) {
+	return c.GetCirculationAtLayer(db, c.CurrentLayer())
+}
+
+// GetCirculationAtLayer returns vested amount at layer plus the sum of rewards up to and including it.
+func (c *Client) GetCirculationAtLayer(db sql.Executor, layer int64) (*Circulation, error) {
 	circulation := &Circulation{
-		Circulation: 0,
-	}
-	if !c.Testnet {
-		accumulatedVest := vesting.AccumulatedVestAtLayer(c.NodeClock.CurrentLayer().Uint32())
-		circulation.Circulation = accumulatedVest
+		Layer:       uint32(layer),
+		Circulation: c.vestedAtLayer(layer),
 	}
 
-	rewardsSum, _, err := c.GetRewardsSum(db)
+	var rewardsSum uint64
+	_, err := db.Exec(`SELECT SUM(total_reward) FROM rewards WHERE layer <= ?1`,
+		func(stmt *sql.Statement) {
+			stmt.BindInt64(1, layer)
+		},
+		func(stmt *sql.Statement) bool {
+			rewardsSum = uint64(stmt.ColumnInt64(0))
+			return true
+		})
 	if err != nil {
-		log.Warning("failed to get rewards count: %v", err)
+		log.Warning("failed to get rewards sum: %v", err)
 		return nil, err
 	}
 	circulation.Circulation += rewardsSum
 
 	return circulation, nil
 }
+
+type CirculationHistory struct {
+	Epochs []EpochCirculation `json:"epochs"`
+}
+
+// EpochCirculation is the circulation at the last layer of an epoch,
+// or at the current layer for the current epoch.
+type EpochCirculation struct {
+	Epoch       uint32 `json:"epoch"`
+	Layer       uint32 `json:"layer"`
+	Circulation uint64 `json:"circulation"`
+}
+
+func (c *Client) GetCirculationHistory(db sql.Executor, from, to, layersPerEpoch int64) (*CirculationHistory, error) {
+	current := c.CurrentLayer()
+	history := &CirculationHistory{
+		Epochs: make([]EpochCirculation, 0, to-from+1),
+	}
+	rewards := make([]uint64, to-from+1)
+
+	// rewards before the range are the base of the running sum
+	var base uint64
+	_, err := db.Exec(`SELECT SUM(total_reward) FROM rewards WHERE layer < ?1`,
+		func(stmt *sql.Statement) {
+			stmt.BindInt64(1, from*layersPerEpoch)
+		},
+		func(stmt *sql.Statement) bool {
+			base = uint64(stmt.ColumnInt64(0))
+			return true
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = db.Exec(`SELECT layer / ?3 AS epoch, SUM(total_reward) FROM rewards
+                                WHERE layer >= ?1 AND layer <= ?2 GROUP BY epoch`,
+		func(stmt *sql.Statement) {
+			stmt.BindInt64(1, from*layersPerEpoch)
+			stmt.BindInt64(2, (to+1)*layersPerEpoch-1)
+			stmt.BindInt64(3, layersPerEpoch)
+		},
+		func(stmt *sql.Statement) bool {
+			if epoch := stmt.ColumnInt64(0); epoch >= from && epoch <= to {
+				rewards[epoch-from] = uint64(stmt.ColumnInt64(1))
+			}
+			return true
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	sum := base
+	for epoch := from; epoch <= to; epoch++ {
+		sum += rewards[epoch-from]
+		layer := min((epoch+1)*layersPerEpoch-1, current)
+		history.Epochs = append(history.Epochs, EpochCirculation{
+			Epoch:       uint32(epoch),
+			Layer:       uint32(layer),
+			Circulation: c.vestedAtLayer(layer) + sum,
+		})
+	}
+
+	return history, nil
+}
+
+func (c *Client) vestedAtLayer(layer int64) uint64 {
+	if c.Testnet {
+		return 0
+	}
+	return vesting.AccumulatedVestAtLayer(uint32(layer))
+}
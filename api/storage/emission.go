@@ -0,0 +1,95 @@
+package storage
+
+import (
+	"github.com/spacemeshos/economics/rewards"
+	"github.com/spacemeshos/go-spacemesh/sql"
+)
+
+type EmissionProjection struct {
+	CurrentEpoch uint32          `json:"current_epoch"`
+	Epochs       []EmissionEpoch `json:"epochs"`
+}
+
+// EmissionEpoch holds figures projected by the economics package for an epoch
+// and, for epochs up to the current one, the actual figures recorded on chain.
+type EmissionEpoch struct {
+	Epoch     uint32           `json:"epoch"`
+	Projected EmissionFigures  `json:"projected"`
+	Actual    *EmissionFigures `json:"actual,omitempty"`
+}
+
+type EmissionFigures struct {
+	Subsidy     uint64 `json:"subsidy"`
+	Vested      uint64 `json:"vested"`
+	Circulation uint64 `json:"circulation"`
+}
+
+// GetEmissionProjection returns projected subsidy, vest and circulation for epochs 0..to,
+// along with actual figures for epochs up to the current one.
+func (c *Client) GetEmissionProjection(db sql.Executor, to, layersPerEpoch int64) (*EmissionProjection, error) {
+	currentEpoch := c.CurrentEpoch(layersPerEpoch)
+	projection := &EmissionProjection{
+		CurrentEpoch: uint32(currentEpoch),
+		Epochs:       make([]EmissionEpoch, 0, to+1),
+	}
+
+	for epoch := int64(0); epoch <= to; epoch++ {
+		end := (epoch+1)*layersPerEpoch - 1
+		subsidy := projectedSubsidyAtLayer(end, layersPerEpoch)
+		vested := c.vestedAtLayer(end)
+		e := EmissionEpoch{
+			Epoch: uint32(epoch),
+			Projected: EmissionFigures{
+				Subsidy:     subsidy - projectedSubsidyAtLayer(end-layersPerEpoch, layersPerEpoch),
+				Vested:      vested - c.vestedAtLayer(max(end-layersPerEpoch, 0)),
+				Circulation: vested + subsidy,
+			},
+		}
+		projection.Epochs = append(projection.Epochs, e)
+	}
+
+	// actual figures use the projection's basis: vest plus subsidy, fees are only transferred
+	actualTo := min(to, currentEpoch)
+	subsidies := make([]uint64, actualTo+1)
+	_, err := db.Exec(`SELECT layer / ?2 AS epoch, SUM(layer_reward) FROM rewards
+                                WHERE layer <= ?1 GROUP BY epoch`,
+		func(stmt *sql.Statement) {
+			stmt.BindInt64(1, (actualTo+1)*layersPerEpoch-1)
+			stmt.BindInt64(2, layersPerEpoch)
+		},
+		func(stmt *sql.Statement) bool {
+			if epoch := stmt.ColumnInt64(0); epoch >= 0 && epoch <= actualTo {
+				subsidies[epoch] = uint64(stmt.ColumnInt64(1))
+			}
+			return true
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	current := c.CurrentLayer()
+	var subsidySum uint64
+	for epoch := int64(0); epoch <= actualTo; epoch++ {
+		start := epoch * layersPerEpoch
+		end := min(start+layersPerEpoch-1, current)
+		vested := c.vestedAtLayer(end)
+		subsidySum += subsidies[epoch]
+		projection.Epochs[epoch].Actual = &EmissionFigures{
+			Subsidy:     subsidies[epoch],
+			Vested:      vested - c.vestedAtLayer(max(start-1, 0)),
+			Circulation: vested + subsidySum,
+		}
+	}
+
+	return projection, nil
+}
+
+// projectedSubsidyAtLayer is the subsidy accumulated up to and including layer.
+// Subsidy is paid from the first effective genesis layer, the last layer of epoch 1.
+func projectedSubsidyAtLayer(layer, layersPerEpoch int64) uint64 {
+	effectiveGenesis := layersPerEpoch*2 - 1
+	if layer < effectiveGenesis {
+		return 0
+	}
+	return rewards.TotalAccumulatedSubsidyAtLayer(uint32(layer - effectiveGenesis))
+}
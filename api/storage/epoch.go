@@ -1,150 +1,236 @@
 package storage
 
 import (
+	"fmt"
 	"math"
 
+	spacemeshv2alpha1 "github.com/spacemeshos/api/release/go/spacemesh/v2alpha1"
 	"github.com/spacemeshos/economics/constants"
 	"github.com/spacemeshos/go-spacemesh/common/types"
-	"github.com/spacemeshos/go-spacemesh/log"
 	"github.com/spacemeshos/go-spacemesh/sql"
-	"github.com/spacemeshos/go-spacemesh/sql/atxs"
-	"github.com/spacemeshos/go-spacemesh/sql/builder"
 
 	"github.com/spacemeshos/explorer-backend/utils"
 )
 
 type EpochStats struct {
-	TransactionsCount uint64 `json:"transactions_count,omitempty"`
-	ActivationsCount  uint64 `json:"activations_count,omitempty"`
-	RewardsCount      uint64 `json:"rewards_count,omitempty"`
-	RewardsSum        uint64 `json:"rewards_sum,omitempty"`
-	NumUnits          uint64 `json:"num_units,omitempty"`
-	SmeshersCount     uint64 `json:"smeshers_count,omitempty"`
-	Decentral         uint64 `json:"decentral,omitempty"`
-	VestedAmount      uint64 `json:"vested_amount,omitempty"`
-	AccountsCount     uint64 `json:"accounts_count,omitempty"`
+	Epoch              uint64            `json:"epoch"`
+	TransactionsCount  uint64            `json:"transactions_count,omitempty"`
+	ActivationsCount   uint64            `json:"activations_count,omitempty"`
+	RewardsCount       uint64            `json:"rewards_count,omitempty"`
+	RewardsSum         uint64            `json:"rewards_sum,omitempty"`
+	SubsidySum         uint64            `json:"subsidy_sum,omitempty"`
+	FeesSum            uint64            `json:"fees_sum,omitempty"`
+	NumUnits           uint64            `json:"num_units,omitempty"`
+	CommittedBytes     uint64            `json:"committed_bytes,omitempty"`
+	CommittedSize      string            `json:"committed_size,omitempty"`
+	SmeshersCount      uint64            `json:"smeshers_count,omitempty"`
+	Decentral          uint64            `json:"decentral,omitempty"`
+	VestedAmount       uint64            `json:"vested_amount,omitempty"`
+	AccountsCount      uint64            `json:"accounts_count,omitempty"`
+	NewAccounts        uint64            `json:"new_accounts,omitempty"`
+	ReturningAccounts  uint64            `json:"returning_accounts,omitempty"`
+	DormantReactivated uint64            `json:"dormant_reactivated,omitempty"`
+	ByType             TxTypes           `json:"by_type,omitempty"`
+	Metrics            *DecentralMetrics `json:"metrics,omitempty"`
+	GroupBy            string            `json:"group_by,omitempty"`
+	ExcludeMalicious   bool              `json:"exclude_malicious,omitempty"`
+	OperatorsCount     uint64            `json:"operators_count,omitempty"`
+	OperatorMetrics    *DecentralMetrics `json:"operator_metrics,omitempty"`
 }
 
-func (c *Client) GetEpochStats(db sql.Executor, epoch, layersPerEpoch int64) (*EpochStats, error) {
-	stats := &EpochStats{
-		TransactionsCount: 0,
-		ActivationsCount:  0,
-		RewardsCount:      0,
-		RewardsSum:        0,
+// DecentralMetrics are standard concentration measures of the space committed in an epoch.
+type DecentralMetrics struct {
+	Gini        float64 `json:"gini"`
+	Nakamoto33  uint64  `json:"nakamoto_33"`
+	Nakamoto50  uint64  `json:"nakamoto_50"`
+	HHI         float64 `json:"hhi"`
+	Theil       float64 `json:"theil"`
+	Top10Share  float64 `json:"top10_share"`
+	Top100Share float64 `json:"top100_share"`
+}
+
+func newDecentralMetrics(smeshers map[string]uint64) *DecentralMetrics {
+	return &DecentralMetrics{
+		Gini:        utils.Gini(smeshers),
+		Nakamoto33:  utils.Nakamoto(smeshers, 0.33),
+		Nakamoto50:  utils.Nakamoto(smeshers, 0.5),
+		HHI:         utils.HHI(smeshers),
+		Theil:       utils.Theil(smeshers),
+		Top10Share:  utils.TopShare(smeshers, 10),
+		Top100Share: utils.TopShare(smeshers, 100),
+	}
+}
+
+// GetEpochStats computes stats of epoch, see GetEpochsStats.
+func (c *Client) GetEpochStats(db sql.Executor, epoch, layersPerEpoch int64,
+	excludeMalicious bool,
+) (*EpochStats, error) {
+	epochs, err := c.GetEpochsStats(db, epoch, epoch, layersPerEpoch, excludeMalicious)
+	if err != nil {
+		return nil, err
 	}
+	return epochs[0], nil
+}
 
+func (c *Client) vestedAmount(epoch, layersPerEpoch int64) uint64 {
 	start := epoch * layersPerEpoch
 	end := start + layersPerEpoch - 1
 	currentEpoch := c.NodeClock.CurrentLayer().Uint32() / uint32(layersPerEpoch)
 
-	if !c.Testnet && end >= constants.VestStart {
-		vestStartEpoch := constants.VestStart / layersPerEpoch
-		if epoch == int64(currentEpoch) {
-			stats.VestedAmount = (uint64(c.NodeClock.CurrentLayer().Uint32()) -
-				uint64(start-1)) * constants.VestPerLayer
-		} else if epoch == vestStartEpoch {
-			stats.VestedAmount = uint64(end-constants.VestStart) * constants.VestPerLayer
-		} else {
-			stats.VestedAmount = uint64(layersPerEpoch) * constants.VestPerLayer
+	if c.Testnet || end < constants.VestStart {
+		return 0
+	}
+	vestStartEpoch := constants.VestStart / layersPerEpoch
+	if epoch == int64(currentEpoch) {
+		return (uint64(c.NodeClock.CurrentLayer().Uint32()) - uint64(start-1)) * constants.VestPerLayer
+	} else if epoch == vestStartEpoch {
+		return uint64(end-constants.VestStart) * constants.VestPerLayer
+	}
+	return uint64(layersPerEpoch) * constants.VestPerLayer
+}
+
+func (c *Client) CurrentEpoch(layersPerEpoch int64) int64 {
+	return c.CurrentLayer() / layersPerEpoch
+}
+
+// GetEpochsStats computes stats of epochs from..to (inclusive) with one grouped query per figure.
+// With excludeMalicious, atxs of identities with a malfeasance proof are left out of activations,
+// units, committed space and smeshers.
+func (c *Client) GetEpochsStats(db sql.Executor, from, to, layersPerEpoch int64,
+	excludeMalicious bool,
+) ([]*EpochStats, error) {
+	epochs := make([]*EpochStats, 0, to-from+1)
+	for epoch := from; epoch <= to; epoch++ {
+		epochs = append(epochs, &EpochStats{
+			Epoch:            uint64(epoch),
+			VestedAmount:     c.vestedAmount(epoch, layersPerEpoch),
+			ByType:           TxTypes{},
+			ExcludeMalicious: excludeMalicious,
+		})
+	}
+	byEpoch := func(stmt *sql.Statement) *EpochStats {
+		epoch := stmt.ColumnInt64(0)
+		if epoch < from || epoch > to {
+			return nil
 		}
+		return epochs[epoch-from]
 	}
 
-	_, err := db.Exec(`SELECT COUNT(*)
-FROM (
-  SELECT distinct id
-  FROM transactions
-  LEFT JOIN transactions_results_addresses
-  ON transactions.id = transactions_results_addresses.tid
-  WHERE layer >= ?1 and layer <= ?2
-);`,
-		func(stmt *sql.Statement) {
-			stmt.BindInt64(1, start)
-			stmt.BindInt64(2, end)
-		},
+	start := from * layersPerEpoch
+	end := (to+1)*layersPerEpoch - 1
+	layersRange := func(stmt *sql.Statement) {
+		stmt.BindInt64(1, start)
+		stmt.BindInt64(2, end)
+		stmt.BindInt64(3, layersPerEpoch)
+	}
+
+	_, err := db.Exec(`SELECT layer / ?3 AS epoch, COUNT(*) FROM transactions
+                                WHERE layer >= ?1 AND layer <= ?2 GROUP BY epoch`,
+		layersRange,
 		func(stmt *sql.Statement) bool {
-			stats.TransactionsCount = uint64(stmt.ColumnInt64(0))
+			if stats := byEpoch(stmt); stats != nil {
+				stats.TransactionsCount = uint64(stmt.ColumnInt64(1))
+			}
 			return true
 		})
 	if err != nil {
 		return nil, err
 	}
 
-	ops := builder.Operations{
-		Filter: []builder.Op{
-			{
-				Field: builder.Epoch,
-				Token: builder.Eq,
-				Value: epoch - 1,
-			},
-		},
-	}
-	count, err := atxs.CountAtxsByOps(db, ops)
+	err = iterateTxContents(db, start, end, func(layer int64,
+		txType spacemeshv2alpha1.Transaction_TransactionType, contents *spacemeshv2alpha1.TransactionContents,
+	) {
+		epochs[layer/layersPerEpoch-from].ByType.add(txType, contents)
+	})
 	if err != nil {
-		log.Err(err)
 		return nil, err
 	}
-	stats.ActivationsCount = uint64(count)
 
-	_, err = db.Exec(`SELECT COUNT(*), SUM(total_reward) FROM rewards WHERE layer >= ?1 and layer <= ?2`,
+	_, err = db.Exec(`SELECT epoch + 1, COUNT(*), SUM(effective_num_units), COUNT(DISTINCT pubkey) FROM atxs
+                                WHERE epoch >= ?1 AND epoch <= ?2`+maliciousFilter("pubkey", excludeMalicious)+`
+                                GROUP BY epoch`,
 		func(stmt *sql.Statement) {
-			stmt.BindInt64(1, start)
-			stmt.BindInt64(2, end)
+			stmt.BindInt64(1, from-1)
+			stmt.BindInt64(2, to-1)
 		},
 		func(stmt *sql.Statement) bool {
-			stats.RewardsCount = uint64(stmt.ColumnInt64(0))
-			stats.RewardsSum = uint64(stmt.ColumnInt64(1))
+			if stats := byEpoch(stmt); stats != nil {
+				stats.ActivationsCount = uint64(stmt.ColumnInt64(1))
+				stats.NumUnits = uint64(stmt.ColumnInt64(2))
+				stats.CommittedBytes = c.committedBytes(stats.NumUnits)
+				stats.CommittedSize = utils.FormatBytes(stats.CommittedBytes)
+				stats.SmeshersCount = uint64(stmt.ColumnInt64(3))
+			}
 			return true
 		})
 	if err != nil {
 		return nil, err
 	}
 
-	_, err = db.Exec(`SELECT SUM(effective_num_units) FROM (SELECT effective_num_units FROM atxs WHERE epoch = ?1)`,
-		func(stmt *sql.Statement) {
-			stmt.BindInt64(1, epoch-1)
-		},
+	_, err = db.Exec(`SELECT layer / ?3 AS epoch, COUNT(*), SUM(total_reward), SUM(layer_reward) FROM rewards
+                                WHERE layer >= ?1 AND layer <= ?2 GROUP BY epoch`,
+		layersRange,
 		func(stmt *sql.Statement) bool {
-			stats.NumUnits = uint64(stmt.ColumnInt64(0))
+			if stats := byEpoch(stmt); stats != nil {
+				stats.RewardsCount = uint64(stmt.ColumnInt64(1))
+				stats.RewardsSum = uint64(stmt.ColumnInt64(2))
+				stats.SubsidySum = uint64(stmt.ColumnInt64(3))
+				stats.FeesSum = stats.RewardsSum - stats.SubsidySum
+			}
 			return true
 		})
 	if err != nil {
 		return nil, err
 	}
 
-	_, err = db.Exec(`SELECT COUNT(*) FROM (SELECT DISTINCT pubkey FROM atxs WHERE epoch = ?1)`,
-		func(stmt *sql.Statement) {
-			stmt.BindInt64(1, epoch-1)
-		},
+	_, err = db.Exec(`SELECT t.layer / ?3 AS epoch, COUNT(DISTINCT a.address)
+                                FROM transactions_results_addresses a
+                                JOIN transactions t ON t.id = a.tid
+                                WHERE t.layer >= ?1 AND t.layer <= ?2 GROUP BY epoch`,
+		layersRange,
 		func(stmt *sql.Statement) bool {
-			stats.SmeshersCount = uint64(stmt.ColumnInt64(0))
+			if stats := byEpoch(stmt); stats != nil {
+				stats.AccountsCount = uint64(stmt.ColumnInt64(1))
+			}
 			return true
 		})
 	if err != nil {
 		return nil, err
 	}
 
-	_, err = db.Exec(`SELECT COUNT(DISTINCT address)
-								FROM transactions_results_addresses
-								WHERE tid IN (
-									SELECT id FROM transactions WHERE layer >= ?1 AND layer <= ?2)`,
-		func(statement *sql.Statement) {
-			statement.BindInt64(1, start)
-			statement.BindInt64(2, end)
-		},
-		func(statement *sql.Statement) bool {
-			stats.AccountsCount = uint64(statement.ColumnInt64(0))
-			return true
+	err = accountsGrowth(db, from, to, layersPerEpoch,
+		func(epoch int64, _, created, returning, reactivated uint64) {
+			stats := epochs[epoch-from]
+			stats.NewAccounts = created
+			stats.ReturningAccounts = returning
+			stats.DormantReactivated = reactivated
 		})
+	if err != nil {
+		return nil, err
+	}
 
-	return stats, err
+	return epochs, nil
 }
 
-func (c *Client) GetEpochDecentralRatio(db sql.Executor, epoch int64) (*EpochStats, error) {
+const (
+	DecentralGroupByCoinbase = "coinbase"
+	DecentralGroupByMarriage = "marriage"
+)
+
+// GetEpochDecentralRatio computes decentralization of the space committed for epoch. If groupBy is set,
+// metrics are additionally computed over operators: identities sharing a coinbase or a married-identity set.
+// With excludeMalicious, identities with a malfeasance proof are left out.
+func (c *Client) GetEpochDecentralRatio(db sql.Executor, epoch int64, groupBy string,
+	excludeMalicious bool,
+) (*EpochStats, error) {
 	stats := &EpochStats{
-		Decentral: 0,
+		Decentral:        0,
+		GroupBy:          groupBy,
+		ExcludeMalicious: excludeMalicious,
 	}
 
-	_, err := db.Exec(`SELECT COUNT(*) FROM (SELECT DISTINCT pubkey FROM atxs WHERE epoch = ?1)`,
+	_, err := db.Exec(`SELECT COUNT(*) FROM (SELECT DISTINCT pubkey FROM atxs WHERE epoch = ?1`+
+		maliciousFilter("pubkey", excludeMalicious)+`)`,
 		func(stmt *sql.Statement) {
 			stmt.BindInt64(1, epoch-1)
 		},
@@ -159,21 +245,45 @@ func (c *Client) GetEpochDecentralRatio(db sql.Executor, epoch int64) (*EpochSta
 	a := math.Min(float64(stats.SmeshersCount), 1e4)
 	// pubkey: commitment size
 	smeshers := make(map[string]uint64)
-	_, err = db.Exec(`SELECT pubkey, effective_num_units FROM atxs WHERE epoch = ?1`,
+	// operator: commitment size
+	operators := make(map[string]uint64)
+	_, err = db.Exec(`SELECT a.pubkey, a.effective_num_units, a.coinbase, COALESCE(m.id, -1) FROM atxs a
+                                LEFT JOIN marriages m ON m.pubkey = a.pubkey
+                                WHERE a.epoch = ?1`+maliciousFilter("a.pubkey", excludeMalicious),
 		func(stmt *sql.Statement) {
 			stmt.BindInt64(1, epoch-1)
 		},
 		func(stmt *sql.Statement) bool {
 			var smesher types.NodeID
 			stmt.ColumnBytes(0, smesher[:])
-			smeshers[smesher.String()] = uint64(stmt.ColumnInt64(1)) * ((c.BitsPerLabel * c.LabelsPerUnit) / 8)
+			size := c.committedBytes(uint64(stmt.ColumnInt64(1)))
+			smeshers[smesher.String()] = size
+
+			operator := smesher.String()
+			switch groupBy {
+			case DecentralGroupByCoinbase:
+				var coinbase types.Address
+				stmt.ColumnBytes(2, coinbase[:])
+				operator = coinbase.String()
+			case DecentralGroupByMarriage:
+				// unmarried identities are operators on their own
+				if marriage := stmt.ColumnInt64(3); marriage >= 0 {
+					operator = fmt.Sprintf("marriage-%d", marriage)
+				}
+			}
+			operators[operator] += size
 			return true
 		})
 	if err != nil {
 		return nil, err
 	}
 
-	stats.Decentral = uint64(100.0 * (0.5*(a*a)/1e8 + 0.5*(1.0-utils.Gini(smeshers))))
+	if groupBy != "" {
+		stats.OperatorsCount = uint64(len(operators))
+		stats.OperatorMetrics = newDecentralMetrics(operators)
+	}
+	stats.Metrics = newDecentralMetrics(smeshers)
+	stats.Decentral = uint64(100.0 * (0.5*(a*a)/1e8 + 0.5*(1.0-stats.Metrics.Gini)))
 
 	return stats, nil
 }
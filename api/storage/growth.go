@@ -0,0 +1,119 @@
+package storage
+
+import (
+	"github.com/spacemeshos/go-spacemesh/sql"
+)
+
+type AccountsGrowth struct {
+	Epochs []EpochAccountsGrowth `json:"epochs"`
+}
+
+type EpochAccountsGrowth struct {
+	Epoch              uint32 `json:"epoch"`
+	ActiveAccounts     uint64 `json:"active_accounts"`
+	NewAccounts        uint64 `json:"new_accounts"`
+	ReturningAccounts  uint64 `json:"returning_accounts"`
+	DormantReactivated uint64 `json:"dormant_reactivated"`
+	TotalAccounts      uint64 `json:"total_accounts"`
+}
+
+// accountActivityQuery selects the epochs every address was a party of a transaction in, as AccountsCount does,
+// and the epoch every address was first seen in, either in a transaction or with a balance update
+// (rewards, genesis accounts). Only layers up to ?4 are read, ?3 is the number of layers per epoch.
+const accountActivityQuery = `WITH activity AS (
+                                  SELECT DISTINCT a.address AS address, t.layer / ?3 AS epoch
+                                  FROM transactions_results_addresses a JOIN transactions t ON t.id = a.tid
+                                  WHERE t.layer <= ?4
+                                ), first_seen AS (
+                                  SELECT address, MIN(epoch) AS epoch FROM (
+                                    SELECT address, epoch FROM activity
+                                    UNION ALL
+                                    SELECT address, layer_updated / ?3 FROM accounts WHERE layer_updated <= ?4
+                                  ) GROUP BY address
+                                )`
+
+// accountsGrowth classifies addresses active in epochs from..to (inclusive) by the epoch they were first seen in:
+// new accounts were first seen in the epoch, returning accounts were also active in the previous epoch
+// and dormant reactivated accounts were seen before but not active in the previous epoch.
+// The three add up to the active accounts of the epoch.
+func accountsGrowth(db sql.Executor, from, to, layersPerEpoch int64,
+	fn func(epoch int64, active, created, returning, reactivated uint64),
+) error {
+	_, err := db.Exec(accountActivityQuery+`
+                                SELECT cur.epoch, COUNT(*),
+                                  SUM(f.epoch = cur.epoch),
+                                  SUM(f.epoch < cur.epoch AND prev.address IS NOT NULL),
+                                  SUM(f.epoch < cur.epoch AND prev.address IS NULL)
+                                FROM activity cur
+                                JOIN first_seen f ON f.address = cur.address
+                                LEFT JOIN activity prev ON prev.address = cur.address AND prev.epoch = cur.epoch - 1
+                                  AND prev.epoch >= ?1 - 1 AND prev.epoch <= ?2 - 1
+                                WHERE cur.epoch >= ?1 AND cur.epoch <= ?2
+                                GROUP BY cur.epoch`,
+		func(stmt *sql.Statement) {
+			stmt.BindInt64(1, from)
+			stmt.BindInt64(2, to)
+			stmt.BindInt64(3, layersPerEpoch)
+			stmt.BindInt64(4, (to+1)*layersPerEpoch-1)
+		},
+		func(stmt *sql.Statement) bool {
+			fn(stmt.ColumnInt64(0),
+				uint64(stmt.ColumnInt64(1)),
+				uint64(stmt.ColumnInt64(2)),
+				uint64(stmt.ColumnInt64(3)),
+				uint64(stmt.ColumnInt64(4)))
+			return true
+		})
+	return err
+}
+
+// GetAccountsGrowth returns new, returning and dormant reactivated accounts for epochs from..to (inclusive)
+// along with the total number of accounts seen up to the end of each epoch.
+func (c *Client) GetAccountsGrowth(db sql.Executor, from, to, layersPerEpoch int64) (*AccountsGrowth, error) {
+	growth := &AccountsGrowth{
+		Epochs: make([]EpochAccountsGrowth, 0, to-from+1),
+	}
+	for epoch := from; epoch <= to; epoch++ {
+		growth.Epochs = append(growth.Epochs, EpochAccountsGrowth{Epoch: uint32(epoch)})
+	}
+
+	err := accountsGrowth(db, from, to, layersPerEpoch,
+		func(epoch int64, active, created, returning, reactivated uint64) {
+			e := &growth.Epochs[epoch-from]
+			e.ActiveAccounts = active
+			e.NewAccounts = created
+			e.ReturningAccounts = returning
+			e.DormantReactivated = reactivated
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	// totals include addresses only seen with a balance update, which are never active
+	firstSeen := make([]uint64, to-from+1)
+	var total uint64
+	_, err = db.Exec(accountActivityQuery+`
+                                SELECT MAX(epoch, ?1 - 1) AS since, COUNT(*) FROM first_seen GROUP BY since`,
+		func(stmt *sql.Statement) {
+			stmt.BindInt64(1, from)
+			stmt.BindInt64(3, layersPerEpoch)
+			stmt.BindInt64(4, (to+1)*layersPerEpoch-1)
+		},
+		func(stmt *sql.Statement) bool {
+			if epoch := stmt.ColumnInt64(0); epoch < from {
+				total = uint64(stmt.ColumnInt64(1))
+			} else if epoch <= to {
+				firstSeen[epoch-from] = uint64(stmt.ColumnInt64(1))
+			}
+			return true
+		})
+	if err != nil {
+		return nil, err
+	}
+	for i := range growth.Epochs {
+		total += firstSeen[i]
+		growth.Epochs[i].TotalAccounts = total
+	}
+
+	return growth, nil
+}
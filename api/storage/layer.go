@@ -1,6 +1,7 @@
 package storage
 
 import (
+	spacemeshv2alpha1 "github.com/spacemeshos/api/release/go/spacemesh/v2alpha1"
 	"github.com/spacemeshos/go-spacemesh/common/types"
 	"github.com/spacemeshos/go-spacemesh/sql"
 	"github.com/spacemeshos/go-spacemesh/sql/builder"
@@ -8,10 +9,13 @@ import (
 )
 
 type LayerStats struct {
-	TransactionsCount uint64 `json:"transactions_count"`
-	TransactionsSum   uint64 `json:"transactions_sum"`
-	RewardsCount      uint64 `json:"rewards_count"`
-	RewardsSum        uint64 `json:"rewards_sum"`
+	TransactionsCount uint64  `json:"transactions_count"`
+	TransactionsSum   uint64  `json:"transactions_sum"`
+	RewardsCount      uint64  `json:"rewards_count"`
+	RewardsSum        uint64  `json:"rewards_sum"`
+	SubsidySum        uint64  `json:"subsidy_sum"`
+	FeesSum           uint64  `json:"fees_sum"`
+	ByType            TxTypes `json:"by_type"`
 }
 
 func (c *Client) GetLayerStats(db sql.Executor, lid int64) (*LayerStats, error) {
@@ -20,6 +24,7 @@ func (c *Client) GetLayerStats(db sql.Executor, lid int64) (*LayerStats, error)
 		TransactionsSum:   0,
 		RewardsCount:      0,
 		RewardsSum:        0,
+		ByType:            TxTypes{},
 	}
 	ops := builder.Operations{
 		Filter: []builder.Op{
@@ -33,7 +38,7 @@ func (c *Client) GetLayerStats(db sql.Executor, lid int64) (*LayerStats, error)
 	err := transactions.IterateTransactionsOps(db, ops, func(tx *types.MeshTransaction,
 		result *types.TransactionResult,
 	) bool {
-		contents, _, err := toTxContents(tx.Raw)
+		contents, txType, err := toTxContents(tx.Raw)
 		if err != nil {
 			return false
 		}
@@ -41,6 +46,7 @@ func (c *Client) GetLayerStats(db sql.Executor, lid int64) (*LayerStats, error)
 		if contents.GetSend() != nil {
 			stats.TransactionsSum += contents.GetSend().GetAmount()
 		}
+		stats.ByType.add(txType, contents)
 
 		stats.TransactionsCount++
 		return true
@@ -49,13 +55,15 @@ func (c *Client) GetLayerStats(db sql.Executor, lid int64) (*LayerStats, error)
 		return nil, err
 	}
 
-	_, err = db.Exec(`SELECT COUNT(*), SUM(total_reward) FROM rewards WHERE layer=?1`,
+	_, err = db.Exec(`SELECT COUNT(*), SUM(total_reward), SUM(layer_reward) FROM rewards WHERE layer=?1`,
 		func(stmt *sql.Statement) {
 			stmt.BindInt64(1, lid)
 		},
 		func(stmt *sql.Statement) bool {
 			stats.RewardsCount = uint64(stmt.ColumnInt64(0))
 			stats.RewardsSum = uint64(stmt.ColumnInt64(1))
+			stats.SubsidySum = uint64(stmt.ColumnInt64(2))
+			stats.FeesSum = stats.RewardsSum - stats.SubsidySum
 			return true
 		})
 	if err != nil {
@@ -75,3 +83,156 @@ func (c *Client) GetLayersCount(db sql.Executor) (count uint64, err error) {
 		})
 	return
 }
+
+func (c *Client) CurrentLayer() int64 {
+	return int64(c.NodeClock.CurrentLayer().Uint32())
+}
+
+type LayerList struct {
+	Layers []LayerSummary `json:"layers"`
+}
+
+type LayerSummary struct {
+	Layer     uint32 `json:"layer"`
+	StartTime int64  `json:"start_time"`
+	LayerStats
+}
+
+// GetLayers lists layers from..to (inclusive) in descending order.
+func (c *Client) GetLayers(db sql.Executor, from, to int64) (*LayerList, error) {
+	layerList := &LayerList{
+		Layers: make([]LayerSummary, 0, to-from+1),
+	}
+	for lid := to; lid >= from; lid-- {
+		layerList.Layers = append(layerList.Layers, LayerSummary{
+			Layer:     uint32(lid),
+			StartTime: c.NodeClock.LayerToTime(types.LayerID(lid)).Unix(),
+			LayerStats: LayerStats{
+				ByType: TxTypes{},
+			},
+		})
+	}
+	byLayer := func(lid int64) *LayerSummary {
+		if lid < from || lid > to {
+			return nil
+		}
+		return &layerList.Layers[to-lid]
+	}
+
+	err := iterateTxContents(db, from, to, func(lid int64,
+		txType spacemeshv2alpha1.Transaction_TransactionType, contents *spacemeshv2alpha1.TransactionContents,
+	) {
+		layer := byLayer(lid)
+		if layer == nil {
+			return
+		}
+		if contents.GetSend() != nil {
+			layer.TransactionsSum += contents.GetSend().GetAmount()
+		}
+		layer.TransactionsCount++
+		layer.ByType.add(txType, contents)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = db.Exec(`SELECT layer, COUNT(*), SUM(total_reward), SUM(layer_reward) FROM rewards
+                                WHERE layer >= ?1 AND layer <= ?2 GROUP BY layer`,
+		func(stmt *sql.Statement) {
+			stmt.BindInt64(1, from)
+			stmt.BindInt64(2, to)
+		},
+		func(stmt *sql.Statement) bool {
+			if layer := byLayer(stmt.ColumnInt64(0)); layer != nil {
+				layer.RewardsCount = uint64(stmt.ColumnInt64(1))
+				layer.RewardsSum = uint64(stmt.ColumnInt64(2))
+				layer.SubsidySum = uint64(stmt.ColumnInt64(3))
+				layer.FeesSum = layer.RewardsSum - layer.SubsidySum
+			}
+			return true
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	return layerList, nil
+}
+
+// LayerDetail is LayerStats together with the layer's consensus state.
+// A layer is empty only if it was applied with the empty block, layers not applied yet are neither.
+type LayerDetail struct {
+	Layer        uint32 `json:"layer"`
+	StartTime    int64  `json:"start_time"`
+	EndTime      int64  `json:"end_time"`
+	Applied      bool   `json:"applied"`
+	AppliedBlock string `json:"applied_block,omitempty"`
+	Empty        bool   `json:"empty"`
+	BallotsCount uint64 `json:"ballots_count"`
+	BlocksCount  uint64 `json:"blocks_count"`
+	Certified    bool   `json:"certified"`
+	LayerStats
+}
+
+func (c *Client) GetLayerDetail(db sql.Executor, lid int64) (*LayerDetail, error) {
+	stats, err := c.GetLayerStats(db, lid)
+	if err != nil {
+		return nil, err
+	}
+	detail := &LayerDetail{
+		Layer:      uint32(lid),
+		StartTime:  c.NodeClock.LayerToTime(types.LayerID(lid)).Unix(),
+		EndTime:    c.NodeClock.LayerToTime(types.LayerID(lid + 1)).Unix(),
+		LayerStats: *stats,
+	}
+
+	_, err = db.Exec(`SELECT applied_block FROM layers WHERE id = ?1 AND applied_block IS NOT NULL`,
+		func(stmt *sql.Statement) {
+			stmt.BindInt64(1, lid)
+		},
+		func(stmt *sql.Statement) bool {
+			var block types.BlockID
+			stmt.ColumnBytes(0, block[:])
+			detail.Applied = true
+			detail.Empty = block == types.EmptyBlockID
+			if !detail.Empty {
+				detail.AppliedBlock = block.String()
+			}
+			return true
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = db.Exec(`SELECT (SELECT COUNT(*) FROM ballots WHERE layer = ?1),
+                                (SELECT COUNT(*) FROM blocks WHERE layer = ?1),
+                                EXISTS (SELECT 1 FROM certificates
+                                  WHERE layer = ?1 AND valid = 1 AND cert IS NOT NULL)`,
+		func(stmt *sql.Statement) {
+			stmt.BindInt64(1, lid)
+		},
+		func(stmt *sql.Statement) bool {
+			detail.BallotsCount = uint64(stmt.ColumnInt64(0))
+			detail.BlocksCount = uint64(stmt.ColumnInt64(1))
+			detail.Certified = stmt.ColumnInt(2) == 1
+			return true
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	return detail, nil
+}
+
+// GetLastAppliedLayer returns the highest layer with an applied block, or -1 if none is applied yet.
+// Rewards and state of layers above it are not final.
+func (c *Client) GetLastAppliedLayer(db sql.Executor) (int64, error) {
+	last := int64(-1)
+	_, err := db.Exec(`SELECT COALESCE(MAX(id), -1) FROM layers WHERE applied_block IS NOT NULL`,
+		func(stmt *sql.Statement) {
+		},
+		func(stmt *sql.Statement) bool {
+			last = stmt.ColumnInt64(0)
+			return true
+		})
+	return last, err
+}
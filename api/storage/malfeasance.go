@@ -0,0 +1,130 @@
+package storage
+
+import (
+	"bytes"
+
+	"github.com/spacemeshos/go-scale"
+	"github.com/spacemeshos/go-spacemesh/common/types"
+	"github.com/spacemeshos/go-spacemesh/malfeasance/wire"
+	"github.com/spacemeshos/go-spacemesh/sql"
+)
+
+// maliciousIdentities selects pubkeys with a malfeasance proof, recorded either in the
+// malfeasance table or in the legacy identities table.
+const maliciousIdentities = `SELECT pubkey FROM malfeasance UNION SELECT pubkey FROM identities`
+
+// maliciousFilter returns a condition on the pubkey column leaving out malicious identities.
+func maliciousFilter(column string, exclude bool) string {
+	if !exclude {
+		return ""
+	}
+	return " AND " + column + " NOT IN (" + maliciousIdentities + ")"
+}
+
+const (
+	ProofTypeUnknown            = "unknown"
+	ProofTypeMarriedToMalicious = "married_to_malicious"
+)
+
+var legacyProofTypes = map[byte]string{
+	wire.MultipleATXs:     "multiple_atxs",
+	wire.MultipleBallots:  "multiple_ballots",
+	wire.HareEquivocation: "hare_equivocation",
+	wire.InvalidPostIndex: "invalid_post_index",
+	wire.InvalidPrevATX:   "invalid_prev_atx",
+}
+
+// proofDomains mirrors malfeasance2.ProofDomain, which is not imported as it pulls in the p2p stack.
+var proofDomains = map[int64]string{
+	0x01: "invalid_activation",
+	0x02: "invalid_ballot",
+	0x03: "invalid_hare_msg",
+}
+
+type MalfeasanceList struct {
+	Identities []MaliciousIdentity `json:"identities"`
+}
+
+type MaliciousIdentity struct {
+	Pubkey    types.NodeID `json:"pubkey"`
+	ProofType string       `json:"proof_type"`
+	Received  int64        `json:"received"`
+	AtxId     string       `json:"atx_id,omitempty"`
+	AtxEpoch  uint32       `json:"atx_epoch,omitempty"`
+	NumUnits  uint64       `json:"num_units,omitempty"`
+}
+
+// GetMalfeasance lists malicious identities, most recently detected first.
+func (c *Client) GetMalfeasance(db sql.Executor, limit, offset uint64) (*MalfeasanceList, error) {
+	list := &MalfeasanceList{
+		Identities: []MaliciousIdentity{},
+	}
+
+	_, err := db.Exec(`SELECT pubkey, received, COALESCE(domain, 0), proof, 0 AS legacy FROM malfeasance
+                                UNION ALL
+                                SELECT pubkey, received, 0, proof, 1 FROM identities
+                                WHERE pubkey NOT IN (SELECT pubkey FROM malfeasance)
+                                ORDER BY received DESC LIMIT ?1 OFFSET ?2`,
+		func(stmt *sql.Statement) {
+			stmt.BindInt64(1, int64(limit))
+			stmt.BindInt64(2, int64(offset))
+		},
+		func(stmt *sql.Statement) bool {
+			var identity MaliciousIdentity
+			stmt.ColumnBytes(0, identity.Pubkey[:])
+			identity.Received = stmt.ColumnInt64(1) / 1e9
+			proof := make([]byte, stmt.ColumnLen(3))
+			stmt.ColumnBytes(3, proof)
+			identity.ProofType = proofType(stmt.ColumnInt64(2), proof,
+				stmt.ColumnInt64(4) == 1)
+			list.Identities = append(list.Identities, identity)
+			return true
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range list.Identities {
+		identity := &list.Identities[i]
+		_, err = db.Exec(`SELECT id, epoch, effective_num_units FROM atxs
+                                WHERE pubkey = ?1 ORDER BY epoch DESC LIMIT 1`,
+			func(stmt *sql.Statement) {
+				stmt.BindBytes(1, identity.Pubkey.Bytes())
+			},
+			func(stmt *sql.Statement) bool {
+				var id types.ATXID
+				stmt.ColumnBytes(0, id[:])
+				identity.AtxId = id.String()
+				identity.AtxEpoch = uint32(stmt.ColumnInt64(1))
+				identity.NumUnits = uint64(stmt.ColumnInt64(2))
+				return false
+			})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return list, nil
+}
+
+// proofType names the kind of proof: legacy proofs carry their type in the encoded proof,
+// newer ones in the domain. Identities without a proof were married to a malicious identity.
+func proofType(domain int64, proof []byte, legacy bool) string {
+	if legacy {
+		var mp wire.MalfeasanceProof
+		if _, err := mp.DecodeScale(scale.NewDecoder(bytes.NewReader(proof))); err != nil {
+			return ProofTypeUnknown
+		}
+		if name, ok := legacyProofTypes[mp.Proof.Type]; ok {
+			return name
+		}
+		return ProofTypeUnknown
+	}
+	if len(proof) == 0 {
+		return ProofTypeMarriedToMalicious
+	}
+	if name, ok := proofDomains[domain]; ok {
+		return name
+	}
+	return ProofTypeUnknown
+}
@@ -0,0 +1,57 @@
+package storage
+
+import (
+	"github.com/spacemeshos/go-spacemesh/sql"
+
+	"github.com/spacemeshos/explorer-backend/utils"
+)
+
+type NetworkCapacity struct {
+	Epochs []EpochCapacity `json:"epochs"`
+}
+
+type EpochCapacity struct {
+	Epoch          uint32 `json:"epoch"`
+	SmeshersCount  uint64 `json:"smeshers_count"`
+	NumUnits       uint64 `json:"num_units"`
+	CommittedBytes uint64 `json:"committed_bytes"`
+	CommittedSize  string `json:"committed_size"`
+}
+
+// GetNetworkCapacity returns the space committed for epochs from..to (inclusive)
+// by atxs published in the preceding epochs.
+func (c *Client) GetNetworkCapacity(db sql.Executor, from, to int64) (*NetworkCapacity, error) {
+	capacity := &NetworkCapacity{
+		Epochs: make([]EpochCapacity, 0, to-from+1),
+	}
+	for epoch := from; epoch <= to; epoch++ {
+		capacity.Epochs = append(capacity.Epochs, EpochCapacity{
+			Epoch:         uint32(epoch),
+			CommittedSize: utils.FormatBytes(0),
+		})
+	}
+
+	_, err := db.Exec(`SELECT epoch + 1, COUNT(DISTINCT pubkey), SUM(effective_num_units) FROM atxs
+                                WHERE epoch >= ?1 AND epoch <= ?2 GROUP BY epoch`,
+		func(stmt *sql.Statement) {
+			stmt.BindInt64(1, from-1)
+			stmt.BindInt64(2, to-1)
+		},
+		func(stmt *sql.Statement) bool {
+			epoch := stmt.ColumnInt64(0)
+			if epoch < from || epoch > to {
+				return true
+			}
+			e := &capacity.Epochs[epoch-from]
+			e.SmeshersCount = uint64(stmt.ColumnInt64(1))
+			e.NumUnits = uint64(stmt.ColumnInt64(2))
+			e.CommittedBytes = c.committedBytes(e.NumUnits)
+			e.CommittedSize = utils.FormatBytes(e.CommittedBytes)
+			return true
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	return capacity, nil
+}
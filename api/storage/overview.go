@@ -5,17 +5,23 @@ import (
 	"github.com/spacemeshos/go-spacemesh/sql"
 )
 
+// Overview holds network totals. NumUnits sums all atxs ever published,
+// while the committed space is the network capacity of the current epoch.
 type Overview struct {
 	AccountsCount     uint64 `json:"accounts_count"`
 	SmeshersCount     uint64 `json:"smeshers_count"`
 	LayersCount       uint64 `json:"layers_count"`
 	RewardsCount      uint64 `json:"rewards_count"`
 	RewardsSum        uint64 `json:"rewards_sum"`
+	SubsidySum        uint64 `json:"subsidy_sum"`
+	FeesSum           uint64 `json:"fees_sum"`
 	TransactionsCount uint64 `json:"transactions_count"`
 	NumUnits          uint64 `json:"num_units"`
+	CommittedBytes    uint64 `json:"committed_bytes"`
+	CommittedSize     string `json:"committed_size"`
 }
 
-func (c *Client) Overview(db sql.Executor) (*Overview, error) {
+func (c *Client) Overview(db sql.Executor, layersPerEpoch int64) (*Overview, error) {
 	overview := &Overview{}
 	accountsCount, err := c.GetAccountsCount(db)
 	if err != nil {
@@ -46,6 +52,14 @@ func (c *Client) Overview(db sql.Executor) (*Overview, error) {
 	overview.RewardsSum = rewardsSum
 	overview.RewardsCount = rewardsCount
 
+	subsidySum, err := c.GetRewardsSubsidySum(db)
+	if err != nil {
+		log.Warning("failed to get rewards subsidy sum: %v", err)
+		return nil, err
+	}
+	overview.SubsidySum = subsidySum
+	overview.FeesSum = rewardsSum - subsidySum
+
 	transactionsCount, err := c.GetTransactionsCount(db)
 	if err != nil {
 		log.Warning("failed to get transactions count: %v", err)
@@ -60,5 +74,14 @@ func (c *Client) Overview(db sql.Executor) (*Overview, error) {
 	}
 	overview.NumUnits = numUnits
 
+	currentEpoch := c.CurrentEpoch(layersPerEpoch)
+	capacity, err := c.GetNetworkCapacity(db, currentEpoch, currentEpoch)
+	if err != nil {
+		log.Warning("failed to get network capacity: %v", err)
+		return nil, err
+	}
+	overview.CommittedBytes = capacity.Epochs[0].CommittedBytes
+	overview.CommittedSize = capacity.Epochs[0].CommittedSize
+
 	return overview, nil
 }
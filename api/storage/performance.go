@@ -0,0 +1,120 @@
+package storage
+
+import (
+	"math"
+
+	"github.com/spacemeshos/go-spacemesh/proposals/util"
+	"github.com/spacemeshos/go-spacemesh/sql"
+)
+
+// SmesherPerformance compares the layers a smesher is expected to be eligible in during an epoch,
+// given its share of the epoch's weight, with the layers it was actually rewarded in.
+type SmesherPerformance struct {
+	Epoch            uint32   `json:"epoch"`
+	NumUnits         uint64   `json:"num_units"`
+	Weight           uint64   `json:"weight"`
+	TotalWeight      uint64   `json:"total_weight"`
+	EligibleSlots    uint32   `json:"eligible_slots"`
+	ElapsedLayers    uint64   `json:"elapsed_layers"`
+	ExpectedLayers   float64  `json:"expected_layers"`
+	BallotsCount     uint64   `json:"ballots_count"`
+	RewardsCount     uint64   `json:"rewards_count"`
+	RewardsSum       uint64   `json:"rewards_sum"`
+	MissedCount      uint64   `json:"missed_count"`
+	MissedLayers     []uint32 `json:"missed_layers"`
+	PerformanceRatio float64  `json:"performance_ratio"`
+}
+
+// GetSmesherPerformance assigns eligible slots the same way the node does and estimates how many
+// distinct applied layers of the epoch they fall into. A smesher casts at most one ballot and receives
+// at most one reward per layer, so rewarded layers are compared with expected layers, not slots.
+// Missed layers are layers where the smesher cast a ballot but received no reward.
+func (c *Client) GetSmesherPerformance(db sql.Executor, pubkey []byte, epoch, layersPerEpoch int64,
+) (*SmesherPerformance, error) {
+	lastApplied, err := c.GetLastAppliedLayer(db)
+	if err != nil {
+		return nil, err
+	}
+	start := epoch * layersPerEpoch
+	end := min(start+layersPerEpoch-1, lastApplied)
+	performance := &SmesherPerformance{
+		Epoch:         uint32(epoch),
+		ElapsedLayers: uint64(max(end-start+1, 0)),
+		MissedLayers:  []uint32{},
+	}
+
+	found := false
+	_, err = db.Exec(`SELECT effective_num_units, weight, (SELECT SUM(weight) FROM atxs WHERE epoch = ?2)
+                                FROM atxs WHERE pubkey = ?1 AND epoch = ?2`,
+		func(stmt *sql.Statement) {
+			stmt.BindBytes(1, pubkey)
+			stmt.BindInt64(2, epoch-1)
+		},
+		func(stmt *sql.Statement) bool {
+			performance.NumUnits = uint64(stmt.ColumnInt64(0))
+			performance.Weight = uint64(stmt.ColumnInt64(1))
+			performance.TotalWeight = uint64(stmt.ColumnInt64(2))
+			found = true
+			return false
+		})
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, ErrSmesherNotFound
+	}
+
+	performance.EligibleSlots, err = util.GetNumEligibleSlots(performance.Weight, 0, performance.TotalWeight,
+		uint32(c.LayerSize), uint32(layersPerEpoch))
+	if err != nil {
+		return nil, err
+	}
+	// slots are drawn uniformly over the layers of the epoch
+	missProbability := math.Pow(1-1/float64(layersPerEpoch), float64(performance.EligibleSlots))
+	performance.ExpectedLayers = float64(performance.ElapsedLayers) * (1 - missProbability)
+
+	_, err = db.Exec(`SELECT b.layer, r.layer IS NOT NULL FROM (SELECT DISTINCT layer FROM ballots
+                                      WHERE pubkey = ?1 AND layer >= ?2 AND layer <= ?3) b
+                                LEFT JOIN rewards r ON r.pubkey = ?1 AND r.layer = b.layer
+                                ORDER BY b.layer`,
+		func(stmt *sql.Statement) {
+			stmt.BindBytes(1, pubkey)
+			stmt.BindInt64(2, start)
+			stmt.BindInt64(3, end)
+		},
+		func(stmt *sql.Statement) bool {
+			performance.BallotsCount++
+			if stmt.ColumnInt(1) == 0 {
+				performance.MissedLayers = append(performance.MissedLayers, uint32(stmt.ColumnInt64(0)))
+			}
+			return true
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = db.Exec(`SELECT COUNT(*), SUM(total_reward) FROM rewards
+                                WHERE pubkey = ?1 AND layer >= ?2 AND layer <= ?3`,
+		func(stmt *sql.Statement) {
+			stmt.BindBytes(1, pubkey)
+			stmt.BindInt64(2, start)
+			stmt.BindInt64(3, end)
+		},
+		func(stmt *sql.Statement) bool {
+			performance.RewardsCount = uint64(stmt.ColumnInt64(0))
+			performance.RewardsSum = uint64(stmt.ColumnInt64(1))
+			return true
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	expected := uint64(math.Round(performance.ExpectedLayers))
+	performance.MissedCount = max(uint64(len(performance.MissedLayers)),
+		expected-min(expected, performance.RewardsCount))
+	if performance.ExpectedLayers > 0 {
+		performance.PerformanceRatio = float64(performance.RewardsCount) / performance.ExpectedLayers
+	}
+
+	return performance, nil
+}
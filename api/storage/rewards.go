@@ -29,3 +29,65 @@ func (c *Client) GetRewardsSumByAddress(db sql.Executor, addr types.Address) (su
 		})
 	return
 }
+
+func (c *Client) GetRewardsSubsidySum(db sql.Executor) (sum uint64, err error) {
+	_, err = db.Exec(`SELECT SUM(layer_reward) FROM rewards`,
+		func(stmt *sql.Statement) {
+		},
+		func(stmt *sql.Statement) bool {
+			sum = uint64(stmt.ColumnInt64(0))
+			return true
+		})
+	return
+}
+
+type RewardsComposition struct {
+	Epochs []EpochRewardsComposition `json:"epochs"`
+}
+
+type EpochRewardsComposition struct {
+	Epoch        uint32  `json:"epoch"`
+	RewardsCount uint64  `json:"rewards_count"`
+	RewardsSum   uint64  `json:"rewards_sum"`
+	SubsidySum   uint64  `json:"subsidy_sum"`
+	FeesSum      uint64  `json:"fees_sum"`
+	FeesShare    float64 `json:"fees_share"`
+}
+
+// GetRewardsComposition splits rewards of epochs from..to (inclusive) into layer subsidy and fees.
+func (c *Client) GetRewardsComposition(db sql.Executor, from, to, layersPerEpoch int64) (*RewardsComposition, error) {
+	composition := &RewardsComposition{
+		Epochs: make([]EpochRewardsComposition, 0, to-from+1),
+	}
+	for epoch := from; epoch <= to; epoch++ {
+		composition.Epochs = append(composition.Epochs, EpochRewardsComposition{Epoch: uint32(epoch)})
+	}
+
+	_, err := db.Exec(`SELECT layer / ?3 AS epoch, COUNT(*), SUM(total_reward), SUM(layer_reward) FROM rewards
+                                WHERE layer >= ?1 AND layer <= ?2 GROUP BY epoch`,
+		func(stmt *sql.Statement) {
+			stmt.BindInt64(1, from*layersPerEpoch)
+			stmt.BindInt64(2, (to+1)*layersPerEpoch-1)
+			stmt.BindInt64(3, layersPerEpoch)
+		},
+		func(stmt *sql.Statement) bool {
+			epoch := stmt.ColumnInt64(0)
+			if epoch < from || epoch > to {
+				return true
+			}
+			item := &composition.Epochs[epoch-from]
+			item.RewardsCount = uint64(stmt.ColumnInt64(1))
+			item.RewardsSum = uint64(stmt.ColumnInt64(2))
+			item.SubsidySum = uint64(stmt.ColumnInt64(3))
+			item.FeesSum = item.RewardsSum - item.SubsidySum
+			if item.RewardsSum > 0 {
+				item.FeesShare = float64(item.FeesSum) / float64(item.RewardsSum)
+			}
+			return true
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	return composition, nil
+}
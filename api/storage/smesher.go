@@ -2,66 +2,168 @@ package storage
 
 import (
 	"errors"
+	"fmt"
+	"strings"
 
 	"github.com/spacemeshos/go-spacemesh/common/types"
 	"github.com/spacemeshos/go-spacemesh/sql"
+
+	"github.com/spacemeshos/explorer-backend/utils"
 )
 
+var ErrSmesherNotFound = errors.New("smesher not found")
+
 type SmesherList struct {
 	Smeshers []Smesher `json:"smeshers"`
 }
 
 type Smesher struct {
-	Pubkey       types.NodeID `json:"pubkey"`
-	Coinbase     string       `json:"coinbase,omitempty"`
-	NumUnits     uint64       `json:"num_units,omitempty"`
-	Atxs         uint64       `json:"atxs"`
-	RewardsCount uint64       `json:"rewards_count,omitempty"`
-	RewardsSum   uint64       `json:"rewards_sum,omitempty"`
+	Pubkey         types.NodeID `json:"pubkey"`
+	Coinbase       string       `json:"coinbase,omitempty"`
+	NumUnits       uint64       `json:"num_units,omitempty"`
+	CommittedBytes uint64       `json:"committed_bytes,omitempty"`
+	CommittedSize  string       `json:"committed_size,omitempty"`
+	Atxs           uint64       `json:"atxs"`
+	FirstEpoch     uint32       `json:"first_epoch,omitempty"`
+	RewardsCount   uint64       `json:"rewards_count,omitempty"`
+	RewardsSum     uint64       `json:"rewards_sum,omitempty"`
+	SubsidySum     uint64       `json:"subsidy_sum,omitempty"`
+	FeesSum        uint64       `json:"fees_sum,omitempty"`
+	Malicious      bool         `json:"malicious"`
+	MaliciousSince int64        `json:"malicious_since,omitempty"`
 }
 
-func (c *Client) GetSmeshers(db sql.Executor, limit, offset uint64) (*SmesherList, error) {
-	smesherList := &SmesherList{
-		Smeshers: []Smesher{},
+const (
+	SmesherSortPubkey     = "pubkey"
+	SmesherSortNumUnits   = "num_units"
+	SmesherSortRewardsSum = "rewards_sum"
+	SmesherSortAtxs       = "atxs"
+	SmesherSortFirstEpoch = "first_epoch"
+
+	OrderAsc  = "asc"
+	OrderDesc = "desc"
+)
+
+var smesherSortColumns = map[string]string{
+	SmesherSortPubkey:     "a.pubkey",
+	SmesherSortNumUnits:   "a.effective_num_units",
+	SmesherSortRewardsSum: "rewards_sum",
+	SmesherSortAtxs:       "a.atxs",
+	SmesherSortFirstEpoch: "first_epoch",
+}
+
+// SmesherQuery sorts and filters smesher listings. Zero value lists all smeshers by pubkey.
+type SmesherQuery struct {
+	Sort     string
+	Order    string
+	Coinbase *types.Address
+	MinUnits uint64
+	MaxUnits uint64
+}
+
+func (q SmesherQuery) Validate() error {
+	if _, ok := smesherSortColumns[q.Sort]; q.Sort != "" && !ok {
+		return fmt.Errorf("unknown sort %q", q.Sort)
+	}
+	if q.Order != "" && q.Order != OrderAsc && q.Order != OrderDesc {
+		return fmt.Errorf("unknown order %q", q.Order)
+	}
+	if q.MaxUnits != 0 && q.MaxUnits < q.MinUnits {
+		return errors.New("max_units is less than min_units")
 	}
+	return nil
+}
 
-	_, err := db.Exec(`SELECT pubkey, COUNT(*) as atxs FROM atxs 
-                                GROUP BY pubkey ORDER BY pubkey ASC, epoch DESC LIMIT ?1 OFFSET ?2;`,
-		func(stmt *sql.Statement) {
-			stmt.BindInt64(1, int64(limit))
-			stmt.BindInt64(2, int64(offset))
-		},
-		func(stmt *sql.Statement) bool {
-			var smesher Smesher
-			stmt.ColumnBytes(0, smesher.Pubkey[:])
-			smesher.Atxs = uint64(stmt.ColumnInt64(1))
-			smesherList.Smeshers = append(smesherList.Smeshers, smesher)
-			return true
-		})
-	if err != nil {
-		return nil, err
+// String is used as a cache key.
+func (q SmesherQuery) String() string {
+	coinbase := ""
+	if q.Coinbase != nil {
+		coinbase = q.Coinbase.String()
 	}
+	return fmt.Sprintf("%s-%s-%s-%d-%d", q.Sort, q.Order, coinbase, q.MinUnits, q.MaxUnits)
+}
 
-	return smesherList, err
+func (q SmesherQuery) orderBy() string {
+	column, ok := smesherSortColumns[q.Sort]
+	if !ok {
+		column = smesherSortColumns[SmesherSortPubkey]
+	}
+	order := "ASC"
+	if q.Order == OrderDesc {
+		order = "DESC"
+	}
+	return fmt.Sprintf("%s %s, a.pubkey ASC", column, order)
+}
+
+func (c *Client) GetSmeshers(db sql.Executor, limit, offset uint64, query SmesherQuery) (*SmesherList, error) {
+	return c.getSmeshers(db, "", nil, limit, offset, query)
 }
 
-func (c *Client) GetSmeshersByEpoch(db sql.Executor, limit, offset, epoch uint64) (*SmesherList, error) {
+func (c *Client) GetSmeshersByEpoch(db sql.Executor, limit, offset, epoch uint64,
+	query SmesherQuery,
+) (*SmesherList, error) {
+	return c.getSmeshers(db, "WHERE epoch = ?1", []any{int64(epoch - 1)}, limit, offset, query)
+}
+
+// getSmeshers lists smeshers with coinbase and units of their latest atx matching atxsFilter.
+func (c *Client) getSmeshers(db sql.Executor, atxsFilter string, args []any,
+	limit, offset uint64, query SmesherQuery,
+) (*SmesherList, error) {
 	smesherList := &SmesherList{
 		Smeshers: []Smesher{},
 	}
 
-	_, err := db.Exec(`SELECT DISTINCT pubkey, COUNT(*) as atxs FROM atxs 
-                                         WHERE epoch = ?1 GROUP BY pubkey 
-                                                          ORDER BY pubkey ASC, epoch DESC LIMIT ?2 OFFSET ?3;`,
+	var conditions []string
+	if query.Coinbase != nil {
+		args = append(args, query.Coinbase.Bytes())
+		conditions = append(conditions, fmt.Sprintf("a.coinbase = ?%d", len(args)))
+	}
+	if query.MinUnits > 0 {
+		args = append(args, int64(query.MinUnits))
+		conditions = append(conditions, fmt.Sprintf("a.effective_num_units >= ?%d", len(args)))
+	}
+	if query.MaxUnits > 0 {
+		args = append(args, int64(query.MaxUnits))
+		conditions = append(conditions, fmt.Sprintf("a.effective_num_units <= ?%d", len(args)))
+	}
+	where := ""
+	if len(conditions) > 0 {
+		where = "WHERE " + strings.Join(conditions, " AND ")
+	}
+	args = append(args, int64(limit), int64(offset))
+
+	_, err := db.Exec(fmt.Sprintf(`SELECT a.pubkey, a.coinbase, a.effective_num_units, a.atxs,
+                                (SELECT MIN(epoch) FROM atxs f WHERE f.pubkey = a.pubkey) AS first_epoch,
+                                COALESCE(r.rewards_count, 0), COALESCE(r.rewards_sum, 0) AS rewards_sum,
+                                COALESCE(r.subsidy_sum, 0),
+                                COALESCE((SELECT received FROM malfeasance m WHERE m.pubkey = a.pubkey),
+                                      (SELECT received FROM identities i WHERE i.pubkey = a.pubkey), 0)
+                                FROM (SELECT pubkey, coinbase, effective_num_units, MAX(epoch), COUNT(*) AS atxs
+                                      FROM atxs %s GROUP BY pubkey) a
+                                LEFT JOIN (SELECT pubkey, COUNT(*) AS rewards_count, SUM(total_reward) AS rewards_sum,
+                                      SUM(layer_reward) AS subsidy_sum
+                                      FROM rewards GROUP BY pubkey) r ON r.pubkey = a.pubkey
+                                %s ORDER BY %s LIMIT ?%d OFFSET ?%d;`,
+		atxsFilter, where, query.orderBy(), len(args)-1, len(args)),
 		func(stmt *sql.Statement) {
-			stmt.BindInt64(1, int64(epoch-1))
-			stmt.BindInt64(2, int64(limit))
-			stmt.BindInt64(3, int64(offset))
+			bindArgs(stmt, args)
 		},
 		func(stmt *sql.Statement) bool {
 			var smesher Smesher
 			stmt.ColumnBytes(0, smesher.Pubkey[:])
-			smesher.Atxs = uint64(stmt.ColumnInt64(1))
+			var coinbase types.Address
+			stmt.ColumnBytes(1, coinbase[:])
+			smesher.Coinbase = coinbase.String()
+			smesher.NumUnits = uint64(stmt.ColumnInt64(2))
+			smesher.CommittedBytes = c.committedBytes(smesher.NumUnits)
+			smesher.CommittedSize = utils.FormatBytes(smesher.CommittedBytes)
+			smesher.Atxs = uint64(stmt.ColumnInt64(3))
+			smesher.FirstEpoch = uint32(stmt.ColumnInt64(4))
+			smesher.RewardsCount = uint64(stmt.ColumnInt64(5))
+			smesher.RewardsSum = uint64(stmt.ColumnInt64(6))
+			smesher.SubsidySum = uint64(stmt.ColumnInt64(7))
+			smesher.FeesSum = smesher.RewardsSum - smesher.SubsidySum
+			smesher.setMaliciousSince(stmt.ColumnInt64(8))
 			smesherList.Smeshers = append(smesherList.Smeshers, smesher)
 			return true
 		})
@@ -72,6 +174,19 @@ func (c *Client) GetSmeshersByEpoch(db sql.Executor, limit, offset, epoch uint64
 	return smesherList, err
 }
 
+func bindArgs(stmt *sql.Statement, args []any) {
+	for i, arg := range args {
+		switch v := arg.(type) {
+		case int64:
+			stmt.BindInt64(i+1, v)
+		case []byte:
+			stmt.BindBytes(i+1, v)
+		default:
+			panic(fmt.Sprintf("unexpected bind type %T", arg))
+		}
+	}
+}
+
 func (c *Client) GetSmeshersCount(db sql.Executor) (count uint64, err error) {
 	_, err = db.Exec(`SELECT COUNT(*) FROM (SELECT DISTINCT pubkey FROM atxs)`,
 		func(stmt *sql.Statement) {
@@ -95,6 +210,18 @@ func (c *Client) GetSmeshersByEpochCount(db sql.Executor, epoch uint64) (count u
 	return
 }
 
+func (c *Client) GetSmeshersByCoinbaseCount(db sql.Executor, coinbase types.Address) (count uint64, err error) {
+	_, err = db.Exec(`SELECT COUNT(DISTINCT pubkey) FROM atxs WHERE coinbase = ?1`,
+		func(stmt *sql.Statement) {
+			stmt.BindBytes(1, coinbase.Bytes())
+		},
+		func(stmt *sql.Statement) bool {
+			count = uint64(stmt.ColumnInt64(0))
+			return true
+		})
+	return
+}
+
 func (c *Client) GetSmesher(db sql.Executor, pubkey []byte) (smesher *Smesher, err error) {
 	_, err = db.Exec(`SELECT pubkey, coinbase, effective_num_units, COUNT(*) as atxs FROM atxs 
                                                                WHERE pubkey = ?1 GROUP BY pubkey 
@@ -109,6 +236,8 @@ func (c *Client) GetSmesher(db sql.Executor, pubkey []byte) (smesher *Smesher, e
 			stmt.ColumnBytes(1, coinbase[:])
 			smesher.Coinbase = coinbase.String()
 			smesher.NumUnits = uint64(stmt.ColumnInt64(2))
+			smesher.CommittedBytes = c.committedBytes(smesher.NumUnits)
+			smesher.CommittedSize = utils.FormatBytes(smesher.CommittedBytes)
 			smesher.Atxs = uint64(stmt.ColumnInt64(3))
 			return true
 		})
@@ -116,17 +245,219 @@ func (c *Client) GetSmesher(db sql.Executor, pubkey []byte) (smesher *Smesher, e
 		return smesher, err
 	}
 	if smesher == nil {
-		return nil, errors.New("smesher not found")
+		return nil, ErrSmesherNotFound
 	}
 
-	_, err = db.Exec(`SELECT COUNT(*), SUM(total_reward) FROM rewards WHERE pubkey=?1`,
+	_, err = db.Exec(`SELECT COUNT(*), SUM(total_reward), SUM(layer_reward) FROM rewards WHERE pubkey=?1`,
 		func(stmt *sql.Statement) {
 			stmt.BindBytes(1, pubkey)
 		},
 		func(stmt *sql.Statement) bool {
 			smesher.RewardsCount = uint64(stmt.ColumnInt64(0))
 			smesher.RewardsSum = uint64(stmt.ColumnInt64(1))
+			smesher.SubsidySum = uint64(stmt.ColumnInt64(2))
+			smesher.FeesSum = smesher.RewardsSum - smesher.SubsidySum
+			return true
+		})
+	if err != nil {
+		return smesher, err
+	}
+
+	_, err = db.Exec(`SELECT COALESCE((SELECT received FROM malfeasance WHERE pubkey = ?1),
+                                (SELECT received FROM identities WHERE pubkey = ?1), 0)`,
+		func(stmt *sql.Statement) {
+			stmt.BindBytes(1, pubkey)
+		},
+		func(stmt *sql.Statement) bool {
+			smesher.setMaliciousSince(stmt.ColumnInt64(0))
 			return true
 		})
 	return smesher, err
 }
+
+// setMaliciousSince marks the smesher malicious if a proof was received, given in unix nanoseconds.
+func (s *Smesher) setMaliciousSince(received int64) {
+	if received > 0 {
+		s.Malicious = true
+		s.MaliciousSince = received / 1e9
+	}
+}
+
+type SmesherEpochs struct {
+	Pubkey       types.NodeID   `json:"pubkey"`
+	Epochs       []SmesherEpoch `json:"epochs"`
+	MissedEpochs []uint32       `json:"missed_epochs"`
+}
+
+// SmesherEpoch is an atx of the smesher and rewards it earned in the target epoch.
+type SmesherEpoch struct {
+	Epoch        uint32 `json:"epoch"`
+	PublishEpoch uint32 `json:"publish_epoch"`
+	AtxId        string `json:"atx_id"`
+	NumUnits     uint64 `json:"num_units"`
+	TickCount    uint64 `json:"tick_count"`
+	Coinbase     string `json:"coinbase"`
+	RewardsCount uint64 `json:"rewards_count"`
+	RewardsSum   uint64 `json:"rewards_sum"`
+}
+
+// GetSmesherEpochs lists target epochs of the smesher atxs along with target epochs
+// without an atx, from the first one up to the current epoch.
+func (c *Client) GetSmesherEpochs(db sql.Executor, pubkey []byte, layersPerEpoch int64) (*SmesherEpochs, error) {
+	smesherEpochs := &SmesherEpochs{
+		Epochs:       []SmesherEpoch{},
+		MissedEpochs: []uint32{},
+	}
+	copy(smesherEpochs.Pubkey[:], pubkey)
+
+	_, err := db.Exec(`SELECT id, epoch, effective_num_units, tick_count, coinbase FROM atxs
+                                WHERE pubkey = ?1 ORDER BY epoch ASC`,
+		func(stmt *sql.Statement) {
+			stmt.BindBytes(1, pubkey)
+		},
+		func(stmt *sql.Statement) bool {
+			var id types.ATXID
+			stmt.ColumnBytes(0, id[:])
+			var coinbase types.Address
+			stmt.ColumnBytes(4, coinbase[:])
+			publishEpoch := uint32(stmt.ColumnInt64(1))
+			smesherEpochs.Epochs = append(smesherEpochs.Epochs, SmesherEpoch{
+				Epoch:        publishEpoch + 1,
+				PublishEpoch: publishEpoch,
+				AtxId:        id.String(),
+				NumUnits:     uint64(stmt.ColumnInt64(2)),
+				TickCount:    uint64(stmt.ColumnInt64(3)),
+				Coinbase:     coinbase.String(),
+			})
+			return true
+		})
+	if err != nil {
+		return nil, err
+	}
+	if len(smesherEpochs.Epochs) == 0 {
+		return nil, ErrSmesherNotFound
+	}
+
+	byEpoch := make(map[uint32]*SmesherEpoch, len(smesherEpochs.Epochs))
+	for i := range smesherEpochs.Epochs {
+		byEpoch[smesherEpochs.Epochs[i].Epoch] = &smesherEpochs.Epochs[i]
+	}
+	_, err = db.Exec(`SELECT layer / ?2 AS epoch, COUNT(*), SUM(total_reward) FROM rewards
+                                WHERE pubkey = ?1 GROUP BY epoch`,
+		func(stmt *sql.Statement) {
+			stmt.BindBytes(1, pubkey)
+			stmt.BindInt64(2, layersPerEpoch)
+		},
+		func(stmt *sql.Statement) bool {
+			if epoch, ok := byEpoch[uint32(stmt.ColumnInt64(0))]; ok {
+				epoch.RewardsCount = uint64(stmt.ColumnInt64(1))
+				epoch.RewardsSum = uint64(stmt.ColumnInt64(2))
+			}
+			return true
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	currentEpoch := c.NodeClock.CurrentLayer().Uint32() / uint32(layersPerEpoch)
+	for epoch := smesherEpochs.Epochs[0].Epoch; epoch <= currentEpoch; epoch++ {
+		if _, ok := byEpoch[epoch]; !ok {
+			smesherEpochs.MissedEpochs = append(smesherEpochs.MissedEpochs, epoch)
+		}
+	}
+
+	return smesherEpochs, nil
+}
+
+type CoinbaseSmeshers struct {
+	Coinbase string            `json:"coinbase"`
+	Smeshers []CoinbaseSmesher `json:"smeshers"`
+}
+
+type CoinbaseSmesher struct {
+	Pubkey types.NodeID           `json:"pubkey"`
+	Epochs []CoinbaseSmesherEpoch `json:"epochs"`
+}
+
+// CoinbaseSmesherEpoch holds units of the atx targeting the epoch and rewards paid to the coinbase in it.
+type CoinbaseSmesherEpoch struct {
+	Epoch        uint32 `json:"epoch"`
+	NumUnits     uint64 `json:"num_units"`
+	RewardsCount uint64 `json:"rewards_count"`
+	RewardsSum   uint64 `json:"rewards_sum"`
+}
+
+// GetSmeshersByCoinbase lists identities whose atxs name the coinbase, paginated by identity.
+func (c *Client) GetSmeshersByCoinbase(db sql.Executor, coinbase types.Address, limit, offset uint64,
+	layersPerEpoch int64,
+) (*CoinbaseSmeshers, error) {
+	result := &CoinbaseSmeshers{
+		Coinbase: coinbase.String(),
+		Smeshers: []CoinbaseSmesher{},
+	}
+
+	type key struct {
+		pubkey types.NodeID
+		epoch  uint32
+	}
+	epochs := make(map[key]*CoinbaseSmesherEpoch)
+	_, err := db.Exec(`SELECT pubkey, epoch, effective_num_units FROM atxs
+                                WHERE coinbase = ?1 AND pubkey IN (
+                                    SELECT DISTINCT pubkey FROM atxs WHERE coinbase = ?1
+                                    ORDER BY pubkey ASC LIMIT ?2 OFFSET ?3)
+                                ORDER BY pubkey ASC, epoch ASC`,
+		func(stmt *sql.Statement) {
+			stmt.BindBytes(1, coinbase.Bytes())
+			stmt.BindInt64(2, int64(limit))
+			stmt.BindInt64(3, int64(offset))
+		},
+		func(stmt *sql.Statement) bool {
+			var pubkey types.NodeID
+			stmt.ColumnBytes(0, pubkey[:])
+			if n := len(result.Smeshers); n == 0 || result.Smeshers[n-1].Pubkey != pubkey {
+				result.Smeshers = append(result.Smeshers, CoinbaseSmesher{
+					Pubkey: pubkey,
+					Epochs: []CoinbaseSmesherEpoch{},
+				})
+			}
+			smesher := &result.Smeshers[len(result.Smeshers)-1]
+			smesher.Epochs = append(smesher.Epochs, CoinbaseSmesherEpoch{
+				Epoch:    uint32(stmt.ColumnInt64(1)) + 1,
+				NumUnits: uint64(stmt.ColumnInt64(2)),
+			})
+			return true
+		})
+	if err != nil {
+		return nil, err
+	}
+	if len(result.Smeshers) == 0 {
+		return result, nil
+	}
+	for i := range result.Smeshers {
+		smesher := &result.Smeshers[i]
+		for j := range smesher.Epochs {
+			epochs[key{smesher.Pubkey, smesher.Epochs[j].Epoch}] = &smesher.Epochs[j]
+		}
+	}
+
+	_, err = db.Exec(`SELECT pubkey, layer / ?2 AS epoch, COUNT(*), SUM(total_reward) FROM rewards
+                                WHERE coinbase = ?1 GROUP BY pubkey, epoch`,
+		func(stmt *sql.Statement) {
+			stmt.BindBytes(1, coinbase.Bytes())
+			stmt.BindInt64(2, layersPerEpoch)
+		},
+		func(stmt *sql.Statement) bool {
+			var pubkey types.NodeID
+			stmt.ColumnBytes(0, pubkey[:])
+			if epoch, ok := epochs[key{pubkey, uint32(stmt.ColumnInt64(1))}]; ok {
+				epoch.RewardsCount = uint64(stmt.ColumnInt64(2))
+				epoch.RewardsSum = uint64(stmt.ColumnInt64(3))
+			}
+			return true
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
@@ -2,6 +2,7 @@ package storage
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/spacemeshos/go-spacemesh/common/types"
 	"github.com/spacemeshos/go-spacemesh/sql"
@@ -10,38 +11,80 @@ import (
 )
 
 type DatabaseClient interface {
-	Overview(db sql.Executor) (*Overview, error)
+	Overview(db sql.Executor, layersPerEpoch int64) (*Overview, error)
 
 	GetLayerStats(db sql.Executor, lid int64) (*LayerStats, error)
+	GetLayerDetail(db sql.Executor, lid int64) (*LayerDetail, error)
+	GetLastAppliedLayer(db sql.Executor) (int64, error)
 	GetLayersCount(db sql.Executor) (uint64, error)
+	GetLayers(db sql.Executor, from, to int64) (*LayerList, error)
+	CurrentLayer() int64
 
-	GetEpochStats(db sql.Executor, epoch, layersPerEpoch int64) (*EpochStats, error)
-	GetEpochDecentralRatio(db sql.Executor, epoch int64) (*EpochStats, error)
+	GetEpochStats(db sql.Executor, epoch, layersPerEpoch int64, excludeMalicious bool) (*EpochStats, error)
+	GetEpochsStats(db sql.Executor, from, to, layersPerEpoch int64, excludeMalicious bool) ([]*EpochStats, error)
+	CurrentEpoch(layersPerEpoch int64) int64
+	GetAtxTiming(db sql.Executor, epoch, layersPerEpoch int64) (*AtxTiming, error)
+	GetEpochDecentralRatio(db sql.Executor, epoch int64, groupBy string,
+		excludeMalicious bool) (*EpochStats, error)
+	GetEpochYield(db sql.Executor, epoch, layersPerEpoch int64) (*Yield, error)
 
-	GetSmeshers(db sql.Executor, limit, offset uint64) (*SmesherList, error)
-	GetSmeshersByEpoch(db sql.Executor, limit, offset, epoch uint64) (*SmesherList, error)
+	GetSmeshers(db sql.Executor, limit, offset uint64, query SmesherQuery) (*SmesherList, error)
+	GetSmeshersByEpoch(db sql.Executor, limit, offset, epoch uint64, query SmesherQuery) (*SmesherList, error)
 	GetSmesher(db sql.Executor, pubkey []byte) (*Smesher, error)
+	GetSmesherEpochs(db sql.Executor, pubkey []byte, layersPerEpoch int64) (*SmesherEpochs, error)
+	GetSmesherYield(db sql.Executor, pubkey []byte, epoch, layersPerEpoch int64) (*Yield, error)
+	GetSmesherPerformance(db sql.Executor, pubkey []byte, epoch, layersPerEpoch int64) (*SmesherPerformance, error)
 
 	GetAccountsCount(db sql.Executor) (uint64, error)
 	GetAccountsStats(db sql.Executor, addr types.Address) (*AccountStats, error)
+	GetAccountTransactions(db sql.Executor, addr types.Address, limit uint64,
+		cursor *TxCursor) (*AccountTransactionList, error)
+	GetAccountBalance(db sql.Executor, addr types.Address, layer int64) (*AccountBalance, error)
+	GetAccountBalanceHistory(db sql.Executor, addr types.Address, from, to, layersPerEpoch int64,
+		bucket string) (*BalanceHistory, error)
+	GetTopAccounts(db sql.Executor, limit, offset uint64, atEpoch, layersPerEpoch int64) (*AccountList, error)
+	GetAccountsDistribution(db sql.Executor) (*AccountsDistribution, error)
 
 	GetSmeshersCount(db sql.Executor) (uint64, error)
 	GetSmeshersByEpochCount(db sql.Executor, epoch uint64) (uint64, error)
+	GetMalfeasance(db sql.Executor, limit, offset uint64) (*MalfeasanceList, error)
+	GetSmeshersByCoinbaseCount(db sql.Executor, coinbase types.Address) (uint64, error)
+	GetSmeshersByCoinbase(db sql.Executor, coinbase types.Address, limit, offset uint64,
+		layersPerEpoch int64) (*CoinbaseSmeshers, error)
 
 	GetRewardsSum(db sql.Executor) (uint64, uint64, error)
 	GetRewardsSumByAddress(db sql.Executor, addr types.Address) (sum, count uint64, err error)
+	GetRewardsSubsidySum(db sql.Executor) (uint64, error)
+	GetRewardsComposition(db sql.Executor, from, to, layersPerEpoch int64) (*RewardsComposition, error)
 
 	GetTransactionsCount(db sql.Executor) (uint64, error)
+	GetTransactions(db sql.Executor, limit, offset uint64) (*TransactionList, error)
+	GetTransactionsByLayer(db sql.Executor, lid int64, limit, offset uint64) (*TransactionList, error)
+	GetTransactionsByEpoch(db sql.Executor, epoch, layersPerEpoch int64, limit, offset uint64) (*TransactionList, error)
+	GetTransactionTypes(db sql.Executor, from, to, layersPerEpoch int64) (*TransactionTypes, error)
 	GetTotalNumUnits(db sql.Executor) (uint64, error)
+	GetNetworkCapacity(db sql.Executor, from, to int64) (*NetworkCapacity, error)
+	GetAccountsGrowth(db sql.Executor, from, to, layersPerEpoch int64) (*AccountsGrowth, error)
+
+	GetVaults(db sql.Executor, limit, offset uint64) (*VaultList, error)
+	GetVault(db sql.Executor, addr types.Address) (*Vault, error)
 
 	GetCirculation(db sql.Executor) (*Circulation, error)
+	GetCirculationAtLayer(db sql.Executor, layer int64) (*Circulation, error)
+	GetCirculationHistory(db sql.Executor, from, to, layersPerEpoch int64) (*CirculationHistory, error)
+	GetSupply(db sql.Executor) (*Supply, error)
+	GetEmissionProjection(db sql.Executor, to, layersPerEpoch int64) (*EmissionProjection, error)
+	GetIssuanceAudit(db sql.Executor, epoch, layersPerEpoch int64) (*IssuanceAudit, error)
 }
 
 type Client struct {
-	NodeClock     *timesync.NodeClock
-	Testnet       bool
-	LabelsPerUnit uint64
-	BitsPerLabel  uint64
+	NodeClock      *timesync.NodeClock
+	Testnet        bool
+	LabelsPerUnit  uint64
+	BitsPerLabel   uint64
+	PoetPhaseShift time.Duration
+	PoetCycleGap   time.Duration
+	LayerSize      uint64
 }
 
 func Setup(path string) (db sql.StateDatabase, err error) {
@@ -0,0 +1,86 @@
+package storage
+
+import (
+	"github.com/spacemeshos/economics/constants"
+	"github.com/spacemeshos/economics/vesting"
+	"github.com/spacemeshos/go-spacemesh/sql"
+)
+
+// SupplyComponent is an amount together with a description of how it was derived.
+type SupplyComponent struct {
+	Amount uint64 `json:"amount"`
+	Source string `json:"source"`
+}
+
+// Supply breaks circulation down into its parts: vested + issued_rewards + fees = circulation.
+type Supply struct {
+	Layer             uint32          `json:"layer"`
+	GenesisPremine    SupplyComponent `json:"genesis_premine"`
+	Vested            SupplyComponent `json:"vested"`
+	IssuedRewards     SupplyComponent `json:"issued_rewards"`
+	Fees              SupplyComponent `json:"fees"`
+	Circulation       SupplyComponent `json:"circulation"`
+	LockedInVaults    SupplyComponent `json:"locked_in_vaults"`
+	TotalSupplyCap    SupplyComponent `json:"total_supply_cap"`
+	RemainingEmission SupplyComponent `json:"remaining_emission"`
+}
+
+func (c *Client) GetSupply(db sql.Executor) (*Supply, error) {
+	layer := c.NodeClock.CurrentLayer().Uint32()
+	supply := &Supply{
+		Layer: layer,
+		GenesisPremine: SupplyComponent{
+			Source: "constants.TotalVaulted, the amount placed in vesting vaults at genesis",
+		},
+		Vested: SupplyComponent{
+			Source: "vesting.AccumulatedVestAtLayer at the current layer",
+		},
+		IssuedRewards: SupplyComponent{
+			Source: "sum of layer_reward over all rewards, fees are excluded as they are not new issuance",
+		},
+		Fees: SupplyComponent{
+			Source: "sum of total_reward minus layer_reward over all rewards, fees paid to smeshers",
+		},
+		Circulation: SupplyComponent{
+			Source: "vested plus issued_rewards plus fees, the same figure as /circulation",
+		},
+		LockedInVaults: SupplyComponent{
+			Source: "sum of total_amount minus amount vested at the current layer over all vault accounts",
+		},
+		TotalSupplyCap: SupplyComponent{
+			Amount: constants.TotalIssuance,
+			Source: "constants.TotalIssuance",
+		},
+		RemainingEmission: SupplyComponent{
+			Source: "constants.TotalSubsidy minus issued_rewards",
+		},
+	}
+	if !c.Testnet {
+		supply.GenesisPremine.Amount = constants.TotalVaulted
+		supply.Vested.Amount = vesting.AccumulatedVestAtLayer(layer)
+	}
+
+	subsidySum, err := c.GetRewardsSubsidySum(db)
+	if err != nil {
+		return nil, err
+	}
+	supply.IssuedRewards.Amount = subsidySum
+
+	rewardsSum, _, err := c.GetRewardsSum(db)
+	if err != nil {
+		return nil, err
+	}
+	supply.Fees.Amount = rewardsSum - subsidySum
+	supply.Circulation.Amount = supply.Vested.Amount + rewardsSum
+	if subsidySum < constants.TotalSubsidy {
+		supply.RemainingEmission.Amount = constants.TotalSubsidy - subsidySum
+	}
+
+	locked, err := c.lockedInVaults(db)
+	if err != nil {
+		return nil, err
+	}
+	supply.LockedInVaults.Amount = locked
+
+	return supply, nil
+}
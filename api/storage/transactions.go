@@ -2,6 +2,7 @@ package storage
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
 
 	spacemeshv2alpha1 "github.com/spacemeshos/api/release/go/spacemesh/v2alpha1"
@@ -14,6 +15,9 @@ import (
 	"github.com/spacemeshos/go-spacemesh/genvm/templates/vesting"
 	"github.com/spacemeshos/go-spacemesh/genvm/templates/wallet"
 	"github.com/spacemeshos/go-spacemesh/sql"
+	"github.com/spacemeshos/go-spacemesh/sql/builder"
+	"github.com/spacemeshos/go-spacemesh/sql/transactions"
+	"google.golang.org/protobuf/encoding/protojson"
 )
 
 func (c *Client) GetTransactionsCount(db sql.Executor) (count uint64, err error) {
@@ -33,6 +37,214 @@ FROM (
 	return
 }
 
+type TransactionList struct {
+	Transactions []Transaction `json:"transactions"`
+}
+
+type Transaction struct {
+	Id        string          `json:"id"`
+	Principal string          `json:"principal"`
+	Template  string          `json:"template"`
+	Method    uint32          `json:"method"`
+	Type      string          `json:"type"`
+	Contents  json.RawMessage `json:"contents,omitempty"`
+	Fee       uint64          `json:"fee"`
+	Status    string          `json:"status"`
+	Layer     uint32          `json:"layer"`
+}
+
+func (c *Client) GetTransactions(db sql.Executor, limit, offset uint64) (*TransactionList, error) {
+	return c.getTransactions(db, []builder.Op{
+		{
+			Field: builder.Layer,
+			Token: builder.Gt,
+			Value: int64(0),
+		},
+	}, limit, offset)
+}
+
+func (c *Client) GetTransactionsByLayer(db sql.Executor, lid int64, limit, offset uint64) (*TransactionList, error) {
+	return c.getTransactions(db, []builder.Op{
+		{
+			Field: builder.Layer,
+			Token: builder.Eq,
+			Value: lid,
+		},
+	}, limit, offset)
+}
+
+func (c *Client) GetTransactionsByEpoch(db sql.Executor, epoch, layersPerEpoch int64,
+	limit, offset uint64,
+) (*TransactionList, error) {
+	start := epoch * layersPerEpoch
+	end := start + layersPerEpoch - 1
+	return c.getTransactions(db, []builder.Op{
+		{
+			Field: builder.Layer,
+			Token: builder.Gte,
+			Value: start,
+		},
+		{
+			Field: builder.Layer,
+			Token: builder.Lte,
+			Value: end,
+		},
+	}, limit, offset)
+}
+
+func (c *Client) getTransactions(db sql.Executor, filter []builder.Op,
+	limit, offset uint64,
+) (*TransactionList, error) {
+	txList := &TransactionList{
+		Transactions: []Transaction{},
+	}
+
+	ops := builder.Operations{
+		Filter: filter,
+		Modifiers: []builder.Modifier{
+			{
+				Key:   builder.OrderBy,
+				Value: "layer DESC, id",
+			},
+			{
+				Key:   builder.Limit,
+				Value: int64(limit),
+			},
+			{
+				Key:   builder.Offset,
+				Value: int64(offset),
+			},
+		},
+	}
+	err := transactions.IterateTransactionsOps(db, ops, func(tx *types.MeshTransaction,
+		result *types.TransactionResult,
+	) bool {
+		txList.Transactions = append(txList.Transactions, toTransaction(tx, result))
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return txList, nil
+}
+
+func toTransaction(tx *types.MeshTransaction, result *types.TransactionResult) Transaction {
+	t := Transaction{
+		Id:     tx.ID.String(),
+		Type:   spacemeshv2alpha1.Transaction_TRANSACTION_TYPE_UNSPECIFIED.String(),
+		Status: "pending",
+		Layer:  tx.LayerID.Uint32(),
+	}
+	if tx.TxHeader != nil {
+		t.Principal = tx.Principal.String()
+		t.Template = tx.TemplateAddress.String()
+		t.Method = uint32(tx.Method)
+	}
+	if result != nil {
+		t.Fee = result.Fee
+		t.Status = result.Status.String()
+	}
+
+	// undecodable transactions are still listed, just without contents
+	contents, txType, err := toTxContents(tx.Raw)
+	if err != nil {
+		return t
+	}
+	t.Type = txType.String()
+	opts := protojson.MarshalOptions{UseProtoNames: true}
+	if raw, err := opts.Marshal(contents); err == nil {
+		t.Contents = raw
+	}
+
+	return t
+}
+
+// TxTypeStats is the number and summed amount of transactions of a single type.
+type TxTypeStats struct {
+	Count uint64 `json:"count"`
+	Sum   uint64 `json:"sum"`
+}
+
+// TxTypes maps spacemeshv2alpha1.Transaction_TransactionType names to their stats.
+type TxTypes map[string]*TxTypeStats
+
+func (t TxTypes) add(txType spacemeshv2alpha1.Transaction_TransactionType,
+	contents *spacemeshv2alpha1.TransactionContents,
+) {
+	stats, ok := t[txType.String()]
+	if !ok {
+		stats = &TxTypeStats{}
+		t[txType.String()] = stats
+	}
+	stats.Count++
+	stats.Sum += txAmount(contents)
+}
+
+// txAmount is the amount moved by a transaction, zero for spawns.
+func txAmount(contents *spacemeshv2alpha1.TransactionContents) uint64 {
+	switch {
+	case contents.GetSend() != nil:
+		return contents.GetSend().GetAmount()
+	case contents.GetDrainVault() != nil:
+		return contents.GetDrainVault().GetAmount()
+	}
+	return 0
+}
+
+// iterateTxContents decodes transactions of layers from..to (inclusive).
+// Undecodable transactions are passed with an unspecified type and empty contents.
+func iterateTxContents(db sql.Executor, from, to int64, fn func(layer int64,
+	txType spacemeshv2alpha1.Transaction_TransactionType, contents *spacemeshv2alpha1.TransactionContents),
+) error {
+	_, err := db.Exec(`SELECT layer, tx FROM transactions WHERE layer >= ?1 AND layer <= ?2`,
+		func(stmt *sql.Statement) {
+			stmt.BindInt64(1, from)
+			stmt.BindInt64(2, to)
+		},
+		func(stmt *sql.Statement) bool {
+			raw := make([]byte, stmt.ColumnLen(1))
+			stmt.ColumnBytes(1, raw)
+			contents, txType, _ := toTxContents(raw)
+			fn(stmt.ColumnInt64(0), txType, contents)
+			return true
+		})
+	return err
+}
+
+type TransactionTypes struct {
+	Epochs []EpochTransactionTypes `json:"epochs"`
+}
+
+type EpochTransactionTypes struct {
+	Epoch  uint64  `json:"epoch"`
+	ByType TxTypes `json:"by_type"`
+}
+
+// GetTransactionTypes returns transaction counts and sums by type for epochs from..to (inclusive).
+func (c *Client) GetTransactionTypes(db sql.Executor, from, to, layersPerEpoch int64) (*TransactionTypes, error) {
+	series := &TransactionTypes{
+		Epochs: make([]EpochTransactionTypes, 0, to-from+1),
+	}
+	for epoch := from; epoch <= to; epoch++ {
+		series.Epochs = append(series.Epochs, EpochTransactionTypes{
+			Epoch:  uint64(epoch),
+			ByType: TxTypes{},
+		})
+	}
+
+	err := iterateTxContents(db, from*layersPerEpoch, (to+1)*layersPerEpoch-1, func(layer int64,
+		txType spacemeshv2alpha1.Transaction_TransactionType, contents *spacemeshv2alpha1.TransactionContents,
+	) {
+		series.Epochs[layer/layersPerEpoch-from].ByType.add(txType, contents)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return series, nil
+}
+
 func decodeTxArgs(decoder *scale.Decoder) (uint8, *core.Address, scale.Encodable, error) {
 	reg := registry.New()
 	wallet.Register(reg)
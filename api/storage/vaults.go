@@ -0,0 +1,206 @@
+package storage
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/spacemeshos/go-scale"
+	"github.com/spacemeshos/go-spacemesh/common/types"
+	"github.com/spacemeshos/go-spacemesh/genvm/templates/vault"
+	"github.com/spacemeshos/go-spacemesh/sql"
+	"github.com/spacemeshos/go-spacemesh/sql/builder"
+	"github.com/spacemeshos/go-spacemesh/sql/transactions"
+)
+
+var ErrVaultNotFound = errors.New("vault not found")
+
+type VaultList struct {
+	Vaults []Vault `json:"vaults"`
+}
+
+// Vault is a vesting vault with its schedule evaluated at Layer.
+type Vault struct {
+	Address             string `json:"address"`
+	Owner               string `json:"owner"`
+	TotalAmount         uint64 `json:"total_amount"`
+	InitialUnlockAmount uint64 `json:"initial_unlock_amount"`
+	VestingStart        uint32 `json:"vesting_start"`
+	VestingEnd          uint32 `json:"vesting_end"`
+	Balance             uint64 `json:"balance"`
+	Layer               uint32 `json:"layer"`
+	Unlocked            uint64 `json:"unlocked"`
+	Drained             uint64 `json:"drained"`
+	Locked              uint64 `json:"locked"`
+}
+
+func (c *Client) GetVaults(db sql.Executor, limit, offset uint64) (*VaultList, error) {
+	vaultList := &VaultList{
+		Vaults: []Vault{},
+	}
+
+	var derr error
+	_, err := db.Exec(`SELECT address, balance, state, MAX(layer_updated) FROM accounts
+                                WHERE template = ?1 GROUP BY address ORDER BY address ASC LIMIT ?2 OFFSET ?3`,
+		func(stmt *sql.Statement) {
+			stmt.BindBytes(1, vault.TemplateAddress.Bytes())
+			stmt.BindInt64(2, int64(limit))
+			stmt.BindInt64(3, int64(offset))
+		},
+		func(stmt *sql.Statement) bool {
+			var v *Vault
+			v, derr = c.decodeVault(stmt)
+			if derr != nil {
+				return false
+			}
+			vaultList.Vaults = append(vaultList.Vaults, *v)
+			return true
+		})
+	if err != nil {
+		return nil, err
+	}
+	if derr != nil {
+		return nil, derr
+	}
+
+	if err = c.fillDrained(db, vaultList.Vaults); err != nil {
+		return nil, err
+	}
+
+	return vaultList, nil
+}
+
+func (c *Client) GetVault(db sql.Executor, addr types.Address) (*Vault, error) {
+	var v *Vault
+	var derr error
+	_, err := db.Exec(`SELECT address, balance, state, layer_updated FROM accounts
+                                WHERE address = ?1 AND template = ?2 ORDER BY layer_updated DESC LIMIT 1`,
+		func(stmt *sql.Statement) {
+			stmt.BindBytes(1, addr.Bytes())
+			stmt.BindBytes(2, vault.TemplateAddress.Bytes())
+		},
+		func(stmt *sql.Statement) bool {
+			v, derr = c.decodeVault(stmt)
+			return false
+		})
+	if err != nil {
+		return nil, err
+	}
+	if derr != nil {
+		return nil, derr
+	}
+	if v == nil {
+		return nil, ErrVaultNotFound
+	}
+
+	vaults := []Vault{*v}
+	if err = c.fillDrained(db, vaults); err != nil {
+		return nil, err
+	}
+
+	return &vaults[0], nil
+}
+
+// decodeVault reads address, balance and state columns of a vault account.
+func (c *Client) decodeVault(stmt *sql.Statement) (*Vault, error) {
+	var addr types.Address
+	stmt.ColumnBytes(0, addr[:])
+	state := make([]byte, stmt.ColumnLen(2))
+	stmt.ColumnBytes(2, state)
+
+	var schedule vault.Vault
+	if _, err := schedule.DecodeScale(scale.NewDecoder(bytes.NewReader(state))); err != nil {
+		return nil, err
+	}
+
+	layer := c.NodeClock.CurrentLayer()
+	unlocked := schedule.Vested(layer)
+	return &Vault{
+		Address:             addr.String(),
+		Owner:               schedule.Owner.String(),
+		TotalAmount:         schedule.TotalAmount,
+		InitialUnlockAmount: schedule.InitialUnlockAmount,
+		VestingStart:        schedule.VestingStart.Uint32(),
+		VestingEnd:          schedule.VestingEnd.Uint32(),
+		Balance:             uint64(stmt.ColumnInt64(1)),
+		Layer:               layer.Uint32(),
+		Unlocked:            unlocked,
+		Locked:              schedule.TotalAmount - unlocked,
+	}, nil
+}
+
+// fillDrained sums successful DrainVault transactions of the given vaults.
+func (c *Client) fillDrained(db sql.Executor, vaults []Vault) error {
+	if len(vaults) == 0 {
+		return nil
+	}
+	byAddress := make(map[string]*Vault, len(vaults))
+	addresses := make([][]byte, 0, len(vaults))
+	for i := range vaults {
+		byAddress[vaults[i].Address] = &vaults[i]
+		addr, err := types.StringToAddress(vaults[i].Address)
+		if err != nil {
+			return err
+		}
+		addresses = append(addresses, addr.Bytes())
+	}
+
+	ops := builder.Operations{
+		Filter: []builder.Op{
+			{
+				CustomQuery: "id IN (SELECT tid FROM transactions_results_addresses WHERE address IN (" +
+					placeholders(1, len(addresses)) + "))",
+				Value: addresses,
+			},
+		},
+	}
+	return transactions.IterateTransactionsOps(db, ops, func(tx *types.MeshTransaction,
+		result *types.TransactionResult,
+	) bool {
+		if result == nil || result.Status != types.TransactionSuccess {
+			return true
+		}
+		contents, _, err := toTxContents(tx.Raw)
+		if err != nil || contents.GetDrainVault() == nil {
+			return true
+		}
+		if v, ok := byAddress[contents.GetDrainVault().GetVault()]; ok {
+			v.Drained += contents.GetDrainVault().GetAmount()
+		}
+		return true
+	})
+}
+
+// placeholders returns a comma separated list of n numbered parameters starting at ?from.
+func placeholders(from, n int) string {
+	params := make([]string, n)
+	for i := range params {
+		params[i] = fmt.Sprintf("?%d", from+i)
+	}
+	return strings.Join(params, ", ")
+}
+
+// lockedInVaults sums the amounts not yet vested in all vaults at the current layer.
+func (c *Client) lockedInVaults(db sql.Executor) (uint64, error) {
+	var locked uint64
+	var derr error
+	_, err := db.Exec(`SELECT address, balance, state, MAX(layer_updated) FROM accounts
+                                WHERE template = ?1 GROUP BY address`,
+		func(stmt *sql.Statement) {
+			stmt.BindBytes(1, vault.TemplateAddress.Bytes())
+		},
+		func(stmt *sql.Statement) bool {
+			var v *Vault
+			v, derr = c.decodeVault(stmt)
+			if derr != nil {
+				return false
+			}
+			locked += v.Locked
+			return true
+		})
+	if err != nil {
+		return 0, err
+	}
+	return locked, derr
+}
@@ -0,0 +1,122 @@
+package storage
+
+import (
+	"time"
+
+	"github.com/spacemeshos/go-spacemesh/common/types"
+	"github.com/spacemeshos/go-spacemesh/sql"
+)
+
+const bytesPerTiB = 1 << 40
+
+// Yield is rewards earned in an epoch relative to the space committed for it.
+type Yield struct {
+	Epoch                    uint32  `json:"epoch"`
+	NumUnits                 uint64  `json:"num_units"`
+	RewardsSum               uint64  `json:"rewards_sum"`
+	RewardsPerUnit           float64 `json:"rewards_per_unit"`
+	RewardsPerTiB            float64 `json:"rewards_per_tib"`
+	EpochsPerYear            float64 `json:"epochs_per_year"`
+	AnnualizedRewardsPerUnit float64 `json:"annualized_rewards_per_unit"`
+	AnnualizedRewardsPerTiB  float64 `json:"annualized_rewards_per_tib"`
+}
+
+func (c *Client) unitSize() uint64 {
+	return (c.BitsPerLabel * c.LabelsPerUnit) / 8
+}
+
+// committedBytes is the storage size of numUnits PoST units.
+func (c *Client) committedBytes(numUnits uint64) uint64 {
+	return numUnits * c.unitSize()
+}
+
+func (c *Client) newYield(epoch int64, numUnits, rewardsSum uint64, layersPerEpoch int64) *Yield {
+	layerDuration := c.NodeClock.LayerToTime(types.LayerID(1)).Sub(c.NodeClock.LayerToTime(types.LayerID(0)))
+	y := &Yield{
+		Epoch:      uint32(epoch),
+		NumUnits:   numUnits,
+		RewardsSum: rewardsSum,
+	}
+	if epochDuration := layerDuration * time.Duration(layersPerEpoch); epochDuration > 0 {
+		y.EpochsPerYear = float64(365*24*time.Hour+6*time.Hour) / float64(epochDuration)
+	}
+	if numUnits == 0 {
+		return y
+	}
+	y.RewardsPerUnit = float64(rewardsSum) / float64(numUnits)
+	if unitSize := c.unitSize(); unitSize > 0 {
+		y.RewardsPerTiB = y.RewardsPerUnit * bytesPerTiB / float64(unitSize)
+	}
+	y.AnnualizedRewardsPerUnit = y.RewardsPerUnit * y.EpochsPerYear
+	y.AnnualizedRewardsPerTiB = y.RewardsPerTiB * y.EpochsPerYear
+	return y
+}
+
+// GetEpochYield divides rewards paid in the epoch by units of atxs targeting it.
+func (c *Client) GetEpochYield(db sql.Executor, epoch, layersPerEpoch int64) (*Yield, error) {
+	var numUnits, rewardsSum uint64
+	_, err := db.Exec(`SELECT SUM(effective_num_units) FROM atxs WHERE epoch = ?1`,
+		func(stmt *sql.Statement) {
+			stmt.BindInt64(1, epoch-1)
+		},
+		func(stmt *sql.Statement) bool {
+			numUnits = uint64(stmt.ColumnInt64(0))
+			return true
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = db.Exec(`SELECT SUM(total_reward) FROM rewards WHERE layer >= ?1 AND layer <= ?2`,
+		func(stmt *sql.Statement) {
+			stmt.BindInt64(1, epoch*layersPerEpoch)
+			stmt.BindInt64(2, (epoch+1)*layersPerEpoch-1)
+		},
+		func(stmt *sql.Statement) bool {
+			rewardsSum = uint64(stmt.ColumnInt64(0))
+			return true
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	return c.newYield(epoch, numUnits, rewardsSum, layersPerEpoch), nil
+}
+
+// GetSmesherYield divides rewards the smesher earned in the epoch by units of its atx targeting it.
+func (c *Client) GetSmesherYield(db sql.Executor, pubkey []byte, epoch, layersPerEpoch int64) (*Yield, error) {
+	var numUnits, rewardsSum uint64
+	found := false
+	_, err := db.Exec(`SELECT effective_num_units FROM atxs WHERE pubkey = ?1 AND epoch = ?2`,
+		func(stmt *sql.Statement) {
+			stmt.BindBytes(1, pubkey)
+			stmt.BindInt64(2, epoch-1)
+		},
+		func(stmt *sql.Statement) bool {
+			numUnits = uint64(stmt.ColumnInt64(0))
+			found = true
+			return false
+		})
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, ErrSmesherNotFound
+	}
+
+	_, err = db.Exec(`SELECT SUM(total_reward) FROM rewards WHERE pubkey = ?1 AND layer >= ?2 AND layer <= ?3`,
+		func(stmt *sql.Statement) {
+			stmt.BindBytes(1, pubkey)
+			stmt.BindInt64(2, epoch*layersPerEpoch)
+			stmt.BindInt64(3, (epoch+1)*layersPerEpoch-1)
+		},
+		func(stmt *sql.Statement) bool {
+			rewardsSum = uint64(stmt.ColumnInt64(0))
+			return true
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	return c.newYield(epoch, numUnits, rewardsSum, layersPerEpoch), nil
+}
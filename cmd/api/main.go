@@ -1,6 +1,7 @@
 package main
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"net/http"
@@ -13,6 +14,7 @@ import (
 	"github.com/spacemeshos/address"
 	"github.com/spacemeshos/go-spacemesh/common/types"
 	"github.com/spacemeshos/go-spacemesh/log"
+	"github.com/spacemeshos/go-spacemesh/sql"
 	"github.com/spacemeshos/go-spacemesh/timesync"
 	"github.com/urfave/cli/v2"
 	"go.uber.org/zap"
@@ -40,6 +42,9 @@ var (
 	genesisTimeStringFlag   string
 	layerDuration           time.Duration
 	labelsPerUnit           uint64
+	poetPhaseShift          time.Duration
+	poetCycleGap            time.Duration
+	layerSize               uint64
 	metricsPortFlag         string
 )
 
@@ -120,6 +125,30 @@ var flags = []cli.Flag{
 		Value:       1024,
 		EnvVars:     []string{"SPACEMESH_LABELS_PER_UNIT"},
 	},
+	&cli.DurationFlag{
+		Name:        "poet-phase-shift",
+		Usage:       "Time between the epoch start and the PoET round start",
+		Required:    false,
+		Destination: &poetPhaseShift,
+		Value:       240 * time.Hour,
+		EnvVars:     []string{"SPACEMESH_POET_PHASE_SHIFT"},
+	},
+	&cli.DurationFlag{
+		Name:        "poet-cycle-gap",
+		Usage:       "Time between the PoET round end and the next round start",
+		Required:    false,
+		Destination: &poetCycleGap,
+		Value:       12 * time.Hour,
+		EnvVars:     []string{"SPACEMESH_POET_CYCLE_GAP"},
+	},
+	&cli.Uint64Flag{
+		Name:        "layer-size",
+		Usage:       "Expected number of ballot eligibilities per layer",
+		Required:    false,
+		Destination: &layerSize,
+		Value:       50,
+		EnvVars:     []string{"SPACEMESH_LAYER_SIZE"},
+	},
 	&cli.StringFlag{
 		Name:        "metricsPort",
 		Usage:       ``,
@@ -154,6 +183,93 @@ var flags = []cli.Flag{
 	},
 }
 
+var auditEpoch int64
+
+var commands = []*cli.Command{
+	{
+		Name:  "audit-issuance",
+		Usage: "Compare the subsidy paid in every layer of an epoch with the expected one",
+		Flags: []cli.Flag{
+			&cli.Int64Flag{
+				Name:        "epoch",
+				Usage:       "Epoch to audit",
+				Required:    true,
+				Destination: &auditEpoch,
+			},
+		},
+		Action: auditIssuance,
+	},
+}
+
+// setup opens the node database and creates the storage client for the configured network.
+func setup() (sql.StateDatabase, *storage.Client, error) {
+	if testnetBoolFlag {
+		address.SetAddressConfig("stest")
+		types.SetNetworkHRP("stest")
+		log.Info(`network HRP set to "stest"`)
+	}
+
+	gTime, err := time.Parse(time.RFC3339, genesisTimeStringFlag)
+	if err != nil {
+		return nil, nil, fmt.Errorf("cannot parse genesis time %s: %w", genesisTimeStringFlag, err)
+	}
+
+	clock, err := timesync.NewClock(
+		timesync.WithLayerDuration(layerDuration),
+		timesync.WithTickInterval(1*time.Second),
+		timesync.WithGenesisTime(gTime),
+		timesync.WithLogger(zap.NewNop()),
+	)
+	if err != nil {
+		return nil, nil, fmt.Errorf("cannot create clock: %w", err)
+	}
+
+	db, err := storage.Setup(sqlitePathStringFlag)
+	if err != nil {
+		log.Info("SQLite storage open error %v", err)
+		return nil, nil, err
+	}
+	dbClient := &storage.Client{
+		NodeClock:      clock,
+		Testnet:        testnetBoolFlag,
+		LabelsPerUnit:  labelsPerUnit,
+		BitsPerLabel:   128,
+		PoetPhaseShift: poetPhaseShift,
+		PoetCycleGap:   poetCycleGap,
+		LayerSize:      layerSize,
+	}
+	return db, dbClient, nil
+}
+
+// auditIssuance prints the issuance audit of an epoch as JSON and fails if any layer is flagged.
+func auditIssuance(ctx *cli.Context) error {
+	if auditEpoch < 0 {
+		return fmt.Errorf("invalid epoch %d", auditEpoch)
+	}
+	db, dbClient, err := setup()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	audit, err := dbClient.GetIssuanceAudit(db, auditEpoch, layersPerEpoch)
+	if err != nil {
+		return fmt.Errorf("cannot audit issuance of epoch %d: %w", auditEpoch, err)
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err = encoder.Encode(audit); err != nil {
+		return err
+	}
+
+	if audit.MissingCount+audit.PartialCount+audit.UnexpectedCount > 0 {
+		return fmt.Errorf("epoch %d: %d layers with missing, %d with partial and %d with unexpected rewards",
+			auditEpoch, audit.MissingCount, audit.PartialCount, audit.UnexpectedCount)
+	}
+	return nil
+}
+
 func main() {
 	app := cli.NewApp()
 	app.Name = "Spacemesh Explorer REST API Server"
@@ -161,44 +277,19 @@ func main() {
 	app.Flags = flags
 	app.Writer = os.Stderr
 
+	app.Commands = commands
+
 	app.Action = func(ctx *cli.Context) error {
-		if testnetBoolFlag {
-			address.SetAddressConfig("stest")
-			types.SetNetworkHRP("stest")
-			log.Info(`network HRP set to "stest"`)
-		}
 		log.Info("layers per epoch: %d", layersPerEpoch)
 		log.Info("debug: %v", debug)
 		log.Info("sqlite path: %s", sqlitePathStringFlag)
 
 		c := cache.New()
 
-		gTime, err := time.Parse(time.RFC3339, genesisTimeStringFlag)
-		if err != nil {
-			return fmt.Errorf("cannot parse genesis time %s: %w", genesisTimeStringFlag, err)
-		}
-
-		clock, err := timesync.NewClock(
-			timesync.WithLayerDuration(layerDuration),
-			timesync.WithTickInterval(1*time.Second),
-			timesync.WithGenesisTime(gTime),
-			timesync.WithLogger(zap.NewNop()),
-		)
+		db, dbClient, err := setup()
 		if err != nil {
-			return fmt.Errorf("cannot create clock: %w", err)
-		}
-
-		db, err := storage.Setup(sqlitePathStringFlag)
-		if err != nil {
-			log.Info("SQLite storage open error %v", err)
 			return err
 		}
-		dbClient := &storage.Client{
-			NodeClock:     clock,
-			Testnet:       testnetBoolFlag,
-			LabelsPerUnit: labelsPerUnit,
-			BitsPerLabel:  128,
-		}
 
 		var wg sync.WaitGroup
 		wg.Add(3)
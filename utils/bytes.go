@@ -0,0 +1,19 @@
+package utils
+
+import "fmt"
+
+var byteUnits = []string{"B", "KiB", "MiB", "GiB", "TiB", "PiB", "EiB"}
+
+// FormatBytes returns a human-readable size in binary units, e.g. "1.50 PiB".
+func FormatBytes(bytes uint64) string {
+	if bytes < 1024 {
+		return fmt.Sprintf("%d B", bytes)
+	}
+	size := float64(bytes)
+	unit := 0
+	for size >= 1024 && unit < len(byteUnits)-1 {
+		size /= 1024
+		unit++
+	}
+	return fmt.Sprintf("%.2f %s", size, byteUnits[unit])
+}
@@ -0,0 +1,26 @@
+package utils
+
+import "testing"
+
+func TestFormatBytes(t *testing.T) {
+	tests := []struct {
+		name  string
+		bytes uint64
+		want  string
+	}{
+		{"zero", 0, "0 B"},
+		{"bytes", 1023, "1023 B"},
+		{"kibibyte", 1024, "1.00 KiB"},
+		{"fraction", 1536, "1.50 KiB"},
+		{"unit size", 64 << 30, "64.00 GiB"},
+		{"pebibytes", 3 << 49, "1.50 PiB"},
+		{"max", ^uint64(0), "16.00 EiB"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := FormatBytes(tc.bytes); got != tc.want {
+				t.Errorf("FormatBytes() = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
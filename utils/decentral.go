@@ -0,0 +1,82 @@
+package utils
+
+import (
+	"math"
+	"sort"
+)
+
+// sortedDesc returns commitment sizes ordered from largest to smallest and their sum.
+func sortedDesc(smeshers map[string]uint64) (CommitmentSizes, float64) {
+	data := make(CommitmentSizes, 0, len(smeshers))
+	var sum float64
+	for _, commitmentSize := range smeshers {
+		data = append(data, commitmentSize)
+		sum += float64(commitmentSize)
+	}
+	sort.Sort(sort.Reverse(data))
+	return data, sum
+}
+
+// Nakamoto returns the fewest smeshers whose combined commitment is more than
+// threshold (0..1) of the total. Returns 0 if there is no commitment at all.
+func Nakamoto(smeshers map[string]uint64, threshold float64) uint64 {
+	data, sum := sortedDesc(smeshers)
+	if sum == 0 {
+		return 0
+	}
+	var acc float64
+	for i, y := range data {
+		acc += float64(y)
+		if acc/sum > threshold {
+			return uint64(i + 1)
+		}
+	}
+	return uint64(len(data))
+}
+
+// HHI returns the Herfindahl-Hirschman index, the sum of squared commitment shares,
+// ranging from 1/n (equal shares) to 1 (single smesher).
+func HHI(smeshers map[string]uint64) float64 {
+	data, sum := sortedDesc(smeshers)
+	if sum == 0 {
+		return 0
+	}
+	var hhi float64
+	for _, y := range data {
+		share := float64(y) / sum
+		hhi += share * share
+	}
+	return hhi
+}
+
+// Theil returns the Theil T index of commitment sizes, ranging from 0 (equal shares)
+// to ln(n) (single smesher). Smeshers without commitment contribute nothing.
+func Theil(smeshers map[string]uint64) float64 {
+	data, sum := sortedDesc(smeshers)
+	if sum == 0 {
+		return 0
+	}
+	mean := sum / float64(len(data))
+	var theil float64
+	for _, y := range data {
+		if y == 0 {
+			continue
+		}
+		ratio := float64(y) / mean
+		theil += ratio * math.Log(ratio)
+	}
+	return theil / float64(len(data))
+}
+
+// TopShare returns the share (0..1) of the total commitment held by the n largest smeshers.
+func TopShare(smeshers map[string]uint64, n int) float64 {
+	data, sum := sortedDesc(smeshers)
+	if sum == 0 {
+		return 0
+	}
+	var top float64
+	for i := 0; i < n && i < len(data); i++ {
+		top += float64(data[i])
+	}
+	return top / sum
+}
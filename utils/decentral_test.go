@@ -0,0 +1,114 @@
+package utils
+
+import (
+	"math"
+	"testing"
+)
+
+const epsilon = 1e-9
+
+func TestNakamoto(t *testing.T) {
+	tests := []struct {
+		name      string
+		smeshers  map[string]uint64
+		threshold float64
+		want      uint64
+	}{
+		{"empty", map[string]uint64{}, 0.5, 0},
+		{"no commitment", map[string]uint64{"a": 0, "b": 0}, 0.5, 0},
+		{"single", map[string]uint64{"a": 10}, 0.5, 1},
+		{"equal half is not a majority", map[string]uint64{"a": 1, "b": 1}, 0.5, 2},
+		{"equal 33", map[string]uint64{"a": 1, "b": 1, "c": 1, "d": 1}, 0.33, 2},
+		{"dominant", map[string]uint64{"a": 60, "b": 20, "c": 20}, 0.5, 1},
+		{"skewed 33", map[string]uint64{"a": 30, "b": 25, "c": 25, "d": 20}, 0.33, 2},
+		{"skewed 50", map[string]uint64{"a": 30, "b": 25, "c": 25, "d": 20}, 0.5, 2},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := Nakamoto(tc.smeshers, tc.threshold); got != tc.want {
+				t.Errorf("Nakamoto() = %d, want %d", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestHHI(t *testing.T) {
+	tests := []struct {
+		name     string
+		smeshers map[string]uint64
+		want     float64
+	}{
+		{"empty", map[string]uint64{}, 0},
+		{"single", map[string]uint64{"a": 5}, 1},
+		{"equal", map[string]uint64{"a": 1, "b": 1, "c": 1, "d": 1}, 0.25},
+		{"skewed", map[string]uint64{"a": 3, "b": 1}, 0.625},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := HHI(tc.smeshers); math.Abs(got-tc.want) > epsilon {
+				t.Errorf("HHI() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestTheil(t *testing.T) {
+	tests := []struct {
+		name     string
+		smeshers map[string]uint64
+		want     float64
+	}{
+		{"empty", map[string]uint64{}, 0},
+		{"single", map[string]uint64{"a": 5}, 0},
+		{"equal", map[string]uint64{"a": 2, "b": 2, "c": 2}, 0},
+		{"all in one of two", map[string]uint64{"a": 4, "b": 0}, math.Log(2)},
+		{"skewed", map[string]uint64{"a": 3, "b": 1}, (1.5*math.Log(1.5) + 0.5*math.Log(0.5)) / 2},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := Theil(tc.smeshers); math.Abs(got-tc.want) > epsilon {
+				t.Errorf("Theil() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestTopShare(t *testing.T) {
+	tests := []struct {
+		name     string
+		smeshers map[string]uint64
+		n        int
+		want     float64
+	}{
+		{"empty", map[string]uint64{}, 10, 0},
+		{"fewer than n", map[string]uint64{"a": 1, "b": 3}, 10, 1},
+		{"top one", map[string]uint64{"a": 1, "b": 3}, 1, 0.75},
+		{"top two", map[string]uint64{"a": 5, "b": 3, "c": 2}, 2, 0.8},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := TopShare(tc.smeshers, tc.n); math.Abs(got-tc.want) > epsilon {
+				t.Errorf("TopShare() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestGini(t *testing.T) {
+	tests := []struct {
+		name     string
+		smeshers map[string]uint64
+		want     float64
+	}{
+		{"empty", map[string]uint64{}, 1},
+		{"equal", map[string]uint64{"a": 2, "b": 2, "c": 2, "d": 2}, 0},
+		{"skewed", map[string]uint64{"a": 3, "b": 1}, 0.25},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := Gini(tc.smeshers); math.Abs(got-tc.want) > epsilon {
+				t.Errorf("Gini() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}